@@ -20,8 +20,6 @@ const (
 	FirmwareVersion       = "1.6.9"
 	DefaultTimeout        = 30 * time.Second
 	DefaultDeviceModel    = "virtual"
-	MinBatteryVoltage     = 3.0
-	MaxBatteryVoltage     = 4.08
 )
 
 // SetupResponse represents the response from /api/setup
@@ -64,57 +62,83 @@ type ModelsResponse struct {
 	Data []DeviceModel `json:"data"`
 }
 
+// sharedTransport is used by every Client (and the API logger) so that several
+// virtual devices talking to the same server reuse connections
+var sharedTransport http.RoundTripper = http.DefaultTransport.(*http.Transport).Clone()
+
+// SharedTransport returns the HTTP transport shared by all API clients
+func SharedTransport() http.RoundTripper {
+	return sharedTransport
+}
+
+// SetSharedTransport replaces the transport used by clients created afterwards,
+// e.g. with a Recorder or Replayer
+func SetSharedTransport(transport http.RoundTripper) {
+	sharedTransport = transport
+}
+
 // Client handles communication with the TRMNL API
 type Client struct {
 	config      *config.Config
 	httpClient  *http.Client
 	verbose     bool
-	refreshRate int // Last known refresh rate
+	refreshRate int                          // Last known refresh rate
+	lastMetrics metrics.SystemMetrics // Metrics sent with the last request
+	provider    metrics.Provider      // Source of battery/WiFi metrics
 }
 
 // PercentageToVoltage converts battery percentage (0-100) to voltage (3.0-4.08V)
-// using a realistic Li-ion battery discharge curve
+// using the default Li-ion battery discharge curve
 func PercentageToVoltage(percentage float64) float64 {
-	// Clamp edge cases
-	if percentage >= 100 {
-		return MaxBatteryVoltage // 4.08V = full charge
-	}
-	if percentage >= 95 {
-		return 4.06 // midpoint of 95-100% block
-	}
-	if percentage >= 90 {
-		return 4.02 // midpoint of 90-95% block
-	}
-	if percentage <= 1 {
-		return MinBatteryVoltage // 3.0V = empty
-	}
-
-	// Linear band: 1-83%
-	// Formula: V = 3.0 + pct * 0.012
-	if percentage > 1 && percentage <= 83 {
-		return MinBatteryVoltage + percentage*0.012
-	}
+	return metrics.DefaultVoltage(percentage)
+}
 
-	// 83-90% band
-	if percentage > 83 && percentage < 90 {
-		return 3.996 + (percentage-83)*0.003429 // ~4.02V at 90%
+// ReportedVoltage returns the Battery-Voltage value sent to the server
+// A voltage measured by the host is preferred; otherwise the percentage is
+// converted using curve (nil uses the default curve)
+func ReportedVoltage(m metrics.SystemMetrics, curve metrics.VoltageCurve) float64 {
+	if m.MeasuredVoltage > 0 {
+		return m.MeasuredVoltage
 	}
-
-	return MinBatteryVoltage
+	return curve.Voltage(m.BatteryVoltage)
 }
 
 // NewClient creates a new TRMNL API client
-func NewClient(cfg *config.Config, verbose bool) *Client {
+// Battery and WiFi metrics are read from provider (nil uses host readings)
+func NewClient(cfg *config.Config, provider metrics.Provider, verbose bool) *Client {
+	if provider == nil {
+		provider = metrics.HostProvider{}
+	}
+
 	return &Client{
 		config: cfg,
 		httpClient: &http.Client{
-			Timeout: DefaultTimeout,
+			Timeout:   DefaultTimeout,
+			Transport: sharedTransport,
 		},
 		verbose:     verbose,
 		refreshRate: 60, // Default refresh rate
+		provider:    provider,
 	}
 }
 
+// collectMetrics reads the metrics provider, falling back to defaults on error
+func (c *Client) collectMetrics() metrics.SystemMetrics {
+	reading, err := c.provider.Read()
+	if err != nil {
+		if c.verbose {
+			fmt.Printf("[API] Failed to read metrics, using defaults: %v\n", err)
+		}
+		reading = metrics.Reading{BatteryPercent: -1}
+	}
+	return reading.Metrics()
+}
+
+// LastMetrics returns the system metrics reported with the most recent request
+func (c *Client) LastMetrics() metrics.SystemMetrics {
+	return c.lastMetrics
+}
+
 // FetchDisplay retrieves the current display information from the API
 func (c *Client) FetchDisplay() (*TerminalResponse, error) {
 	url := c.config.BaseURL + DisplayEndpoint
@@ -133,9 +157,10 @@ func (c *Client) FetchDisplay() (*TerminalResponse, error) {
 	req.Header.Set(authHeader, authValue)
 
 	// Set device metrics headers
-	systemMetrics := metrics.Collect()
+	systemMetrics := c.collectMetrics()
+	c.lastMetrics = systemMetrics
 	batteryPercent := systemMetrics.BatteryVoltage // This is actually percentage (0-100)
-	batteryVoltage := PercentageToVoltage(batteryPercent)
+	batteryVoltage := ReportedVoltage(systemMetrics, c.config.BatteryCurve)
 
 	req.Header.Set("percent_charged", fmt.Sprintf("%.2f", batteryPercent))
 	req.Header.Set("Battery-Voltage", fmt.Sprintf("%.2f", batteryVoltage))
@@ -168,6 +193,9 @@ func (c *Client) FetchDisplay() (*TerminalResponse, error) {
 			fmt.Printf("[API] ID: %s\n", authValue)
 		}
 		fmt.Printf("[API] Battery: %.2f%% (%.2fV), RSSI: %d dBm\n", batteryPercent, batteryVoltage, systemMetrics.RSSI)
+		if systemMetrics.MeasuredVoltage > 0 || systemMetrics.Charging != metrics.ChargingUnknown {
+			fmt.Printf("[API] Measured voltage: %.2fV, Charging: %s\n", systemMetrics.MeasuredVoltage, systemMetrics.Charging)
+		}
 		fmt.Printf("[API] Model: %s, FW-Version: %s\n", modelName, FirmwareVersion)
 		fmt.Printf("[API] Dimensions: %dx%d, Refresh-Rate: %d\n", c.config.WindowWidth, c.config.WindowHeight, c.refreshRate)
 	}
@@ -305,9 +333,10 @@ func (c *Client) FetchCurrentScreen() (*TerminalResponse, error) {
 	req.Header.Set(authHeader, authValue)
 
 	// Set device metrics headers (same as display)
-	systemMetrics := metrics.Collect()
+	systemMetrics := c.collectMetrics()
+	c.lastMetrics = systemMetrics
 	batteryPercent := systemMetrics.BatteryVoltage
-	batteryVoltage := PercentageToVoltage(batteryPercent)
+	batteryVoltage := ReportedVoltage(systemMetrics, c.config.BatteryCurve)
 
 	req.Header.Set("percent_charged", fmt.Sprintf("%.2f", batteryPercent))
 	req.Header.Set("Battery-Voltage", fmt.Sprintf("%.2f", batteryVoltage))
@@ -0,0 +1,374 @@
+package api
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogEndpoint is where device logs are uploaded (answered locally when replaying)
+const LogEndpoint = "/api/log"
+
+// redactedHeaders carry credentials or the device identity and are not recorded
+var redactedHeaders = []string{"Access-Token", "ID", "Authorization", "Cookie", "Set-Cookie", "Proxy-Authorization"}
+
+// redactedFields are JSON body fields carrying credentials, which are not recorded
+var redactedFields = []string{"api_key", "access_token", "token", "password", "secret"}
+
+// exchange is the metadata of a recorded request and its response
+// The bodies are stored next to it in the archive
+type exchange struct {
+	Method         string      `json:"method"`
+	URL            string      `json:"url"`
+	RequestHeader  http.Header `json:"request_header"`
+	RequestBody    string      `json:"request_body,omitempty"` // Archive entry with the request body
+	Time           time.Time   `json:"time"`                   // When the request was sent
+	LatencyMillis  int64       `json:"latency_ms"`             // How long the server took to respond
+	StatusCode     int         `json:"status"`
+	ResponseHeader http.Header `json:"response_header"`
+	ResponseBody   string      `json:"response_body"` // Archive entry with the response body
+}
+
+// Recorder is an HTTP transport that writes every request and response to a
+// session archive (a tar file), for replaying later with a Replayer
+// Credentials in headers and JSON bodies are redacted, and query strings (which
+// may hold signed tokens) are dropped from URLs; images are kept as sent
+type Recorder struct {
+	mu        sync.Mutex
+	transport http.RoundTripper
+	file      *os.File
+	archive   *tar.Writer
+	count     int
+}
+
+// NewRecorder creates the session archive at path, recording requests made through transport
+// The archive is only readable by the user, as it holds the device's screens
+func NewRecorder(path string, transport http.RoundTripper) (*Recorder, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session archive: %w", err)
+	}
+	return &Recorder{
+		transport: transport,
+		file:      file,
+		archive:   tar.NewWriter(file),
+	}, nil
+}
+
+// RoundTrip sends the request and records it with the response
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if req.Body != nil {
+		var err error
+		requestBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(requestBody))
+	}
+
+	start := time.Now()
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	responseBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count++
+	name := fmt.Sprintf("%06d", r.count)
+	ex := exchange{
+		Method:         req.Method,
+		URL:            redactURL(req.URL.String()),
+		RequestHeader:  redactHeader(req.Header),
+		Time:           start,
+		LatencyMillis:  time.Since(start).Milliseconds(),
+		StatusCode:     resp.StatusCode,
+		ResponseHeader: redactHeader(resp.Header),
+		ResponseBody:   name + ".response",
+	}
+	if len(requestBody) > 0 {
+		ex.RequestBody = name + ".request"
+	}
+
+	// A failed write stops the recording, not the request
+	if err := r.write(name, ex, redactBody(requestBody), redactBody(responseBody)); err != nil {
+		log.Printf("Warning: Failed to record %s %s: %v", req.Method, req.URL.Path, err)
+	}
+	return resp, nil
+}
+
+// write adds an exchange and its bodies to the archive
+// The caller must hold r.mu
+func (r *Recorder) write(name string, ex exchange, requestBody, responseBody []byte) error {
+	metadata, err := json.MarshalIndent(ex, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode exchange: %w", err)
+	}
+
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{name + ".json", metadata},
+		{ex.RequestBody, requestBody},
+		{ex.ResponseBody, responseBody},
+	}
+	for _, f := range files {
+		if f.name == "" {
+			continue
+		}
+		header := &tar.Header{Name: f.name, Mode: 0600, Size: int64(len(f.data)), ModTime: ex.Time}
+		if err := r.archive.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := r.archive.Write(f.data); err != nil {
+			return err
+		}
+	}
+	// Flush so the archive is readable even if the process is killed
+	return r.archive.Flush()
+}
+
+// Close finishes the session archive
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.archive.Close(); err != nil {
+		r.file.Close()
+		return fmt.Errorf("failed to finish session archive: %w", err)
+	}
+	return r.file.Close()
+}
+
+// Replayer is an HTTP transport that answers requests from a session archive
+// instead of the network, so the app can be driven offline
+// Responses for each method and path are served in the order they were recorded,
+// after their original latency; speed divides latencies and refresh rates so a
+// session plays back faster. Log uploads are accepted locally
+type Replayer struct {
+	mu        sync.Mutex
+	speed     float64
+	responses map[string][]replayResponse // Unserved responses by method and path
+}
+
+// replayResponse is a recorded response waiting to be served
+type replayResponse struct {
+	exchange
+	body []byte
+}
+
+// NewReplayer loads a session archive written by a Recorder
+// speed is how many times faster than recorded to play (1 for the original timing)
+func NewReplayer(path string, speed float64) (*Replayer, error) {
+	if speed <= 0 {
+		return nil, fmt.Errorf("replay speed must be positive (got %g)", speed)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session archive: %w", err)
+	}
+	defer file.Close()
+
+	var exchanges []exchange
+	bodies := make(map[string][]byte)
+	archive := tar.NewReader(file)
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read session archive: %w", err)
+		}
+		data, err := io.ReadAll(archive)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from session archive: %w", header.Name, err)
+		}
+
+		if strings.HasSuffix(header.Name, ".json") {
+			var ex exchange
+			if err := json.Unmarshal(data, &ex); err != nil {
+				return nil, fmt.Errorf("invalid %s in session archive: %w", header.Name, err)
+			}
+			exchanges = append(exchanges, ex)
+		} else {
+			bodies[header.Name] = data
+		}
+	}
+	if len(exchanges) == 0 {
+		return nil, fmt.Errorf("session archive %s has no recorded requests", path)
+	}
+
+	r := &Replayer{speed: speed, responses: make(map[string][]replayResponse)}
+	for _, ex := range exchanges {
+		key, err := replayKey(ex.Method, ex.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL in session archive: %w", err)
+		}
+		r.responses[key] = append(r.responses[key], replayResponse{exchange: ex, body: bodies[ex.ResponseBody]})
+	}
+	return r, nil
+}
+
+// RoundTrip answers the request with the next recorded response for its method and path
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	if req.Method == http.MethodPost && req.URL.Path == LogEndpoint {
+		return replayResponse{exchange: exchange{StatusCode: http.StatusNoContent}}.response(req), nil
+	}
+
+	key := req.Method + " " + req.URL.Path
+	r.mu.Lock()
+	queue := r.responses[key]
+	if len(queue) == 0 {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("recorded session has no more responses for %s", key)
+	}
+	next := queue[0]
+	r.responses[key] = queue[1:]
+	r.mu.Unlock()
+
+	// Answer after the original latency, unless the request is cancelled first
+	latency := time.Duration(float64(next.LatencyMillis) * float64(time.Millisecond) / r.speed)
+	select {
+	case <-time.After(latency):
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+
+	if r.speed != 1 {
+		next.body = scaleRefreshRate(next.body, r.speed)
+	}
+	return next.response(req), nil
+}
+
+// response builds the HTTP response for a request
+func (rr replayResponse) response(req *http.Request) *http.Response {
+	header := rr.ResponseHeader.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Set("Content-Length", strconv.Itoa(len(rr.body)))
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rr.StatusCode, http.StatusText(rr.StatusCode)),
+		StatusCode:    rr.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(rr.body)),
+		ContentLength: int64(len(rr.body)),
+		Request:       req,
+	}
+}
+
+// replayKey identifies the requests a recorded response can answer: the method and
+// path, so a session recorded against one server can be replayed with any base URL
+func replayKey(method, rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	return method + " " + u.Path, nil
+}
+
+// scaleRefreshRate divides the refresh rate in a JSON response by speed (at least
+// one second), so the app asks for the next screen sooner when replaying faster
+func scaleRefreshRate(body []byte, speed float64) []byte {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return body
+	}
+	var rate float64
+	if err := json.Unmarshal(fields["refresh_rate"], &rate); err != nil || rate <= 0 {
+		return body
+	}
+	fields["refresh_rate"] = json.RawMessage(strconv.Itoa(int(math.Max(1, math.Round(rate/speed)))))
+	scaled, err := json.Marshal(fields)
+	if err != nil {
+		return body
+	}
+	return scaled
+}
+
+// redactBody returns a JSON object body with credentials replaced and query
+// strings dropped from URL fields (e.g. image_url); other bodies are returned as is
+func redactBody(body []byte) []byte {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return body
+	}
+
+	changed := false
+	for name, raw := range fields {
+		var value string
+		if json.Unmarshal(raw, &value) != nil || value == "" {
+			continue
+		}
+		redacted := value
+		if slices.Contains(redactedFields, strings.ToLower(name)) {
+			redacted = "REDACTED"
+		} else if strings.HasSuffix(strings.ToLower(name), "url") {
+			redacted = redactURL(value)
+		}
+		if redacted != value {
+			fields[name], _ = json.Marshal(redacted)
+			changed = true
+		}
+	}
+	if !changed {
+		return body
+	}
+
+	redacted, err := json.Marshal(fields)
+	if err != nil {
+		return body
+	}
+	return redacted
+}
+
+// redactURL drops the query string and fragment of a URL; replaying only matches paths
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.RawQuery == "" && u.Fragment == "") {
+		return rawURL
+	}
+	u.RawQuery, u.ForceQuery, u.Fragment = "", false, ""
+	return u.String()
+}
+
+// redactHeader returns a copy of header with credentials replaced
+func redactHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, name := range redactedHeaders {
+		if redacted.Get(name) != "" {
+			redacted.Set(name, "REDACTED")
+		}
+	}
+	return redacted
+}
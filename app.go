@@ -9,15 +9,20 @@ import (
 	"os/signal"
 	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/semaja2/trmnl-go/api"
 	"github.com/semaja2/trmnl-go/config"
+	"github.com/semaja2/trmnl-go/display"
+	"github.com/semaja2/trmnl-go/history"
 	"github.com/semaja2/trmnl-go/logging"
 	"github.com/semaja2/trmnl-go/metrics"
 	"github.com/semaja2/trmnl-go/models"
 	"github.com/semaja2/trmnl-go/render"
+	"github.com/semaja2/trmnl-go/telemetry"
 )
 
 const (
@@ -32,7 +37,7 @@ const (
 var (
 	// Command-line flags
 	apiKey       = flag.String("api-key", "", "TRMNL API key (for usetrmnl.com)")
-	deviceID     = flag.String("device-id", "", "Device ID (for self-hosted servers)")
+	deviceID     = flag.String("device-id", "", "Device ID as a MAC address, e.g. AA:BB:CC:DD:EE:FF (for self-hosted servers)")
 	macAddress   = flag.String("mac-address", "", "MAC address to use as Device ID (e.g. AA:BB:CC:DD:EE:FF)")
 	netInterface = flag.String("interface", "", "Network interface for MAC address (e.g. en0, eth0)")
 	baseURL      = flag.String("base-url", "", "Base URL for TRMNL API")
@@ -52,8 +57,34 @@ var (
 	useFyne          = flag.Bool("use-fyne", false, "Force use of Fyne GUI (default: native window on macOS)")
 	verbose          = flag.Bool("verbose", false, "Enable verbose logging")
 	logFlushInterval = flag.Int("log-flush-interval", 0, "How often to flush logs to API in seconds (default: 1800/30min, set 60 for dev)")
+	metricsAddr      = flag.String("metrics-addr", "", "Serve Prometheus metrics on this address (e.g. :9100)")
+	simulateBattery  = flag.String("simulate-battery", "", "Simulate battery instead of host readings (fixed, linear, realistic, charging)")
+	simulateRSSI     = flag.String("simulate-rssi", "", "Simulate WiFi signal instead of host readings (fixed, drift, dropout)")
+	metricsProvider  = flag.String("metrics-provider", "", "Metrics source: host, static, simulated or command")
+	metricsCommand   = flag.String("metrics-command", "", "Command printing JSON metrics (used by the command provider)")
 	showVersion      = flag.Bool("version", false, "Show version information")
 	saveConfig       = flag.Bool("save", false, "Save current settings to config file")
+	identitySeed     = flag.String("identity-seed", "", "Derive a stable device ID from this seed instead of the network MAC (overrides a saved device_id)")
+	resetIdentity    = flag.Bool("reset-identity", false, "Forget the saved device ID, API key and device name, then exit")
+	profile          = flag.String("profile", "", "Configuration profile to use (e.g. staging, local)")
+	checkConfigFlag  = flag.Bool("check-config", false, "Validate the configuration, report all problems and exit (non-zero if invalid)")
+	output           = flag.String("output", OutputWindow, "Where to show the display: window, terminal (for SSH sessions), framebuffer (Linux, without X/Wayland) or web (any browser)")
+	terminalGraphics = flag.String("terminal-graphics", display.TerminalGraphicsAuto, "Terminal graphics protocol: auto, kitty, iterm2, sixel or blocks")
+	framebuffer      = flag.String("framebuffer", display.DefaultFramebufferDevice, "Framebuffer device (or a file standing in for one) used by -output framebuffer")
+	framebufferGeom  = flag.String("framebuffer-geometry", "", "Framebuffer geometry WIDTHxHEIGHTxBPP[:STRIDE] (default: read from the device)")
+	webAddr          = flag.String("web-addr", display.DefaultWebAddr, "Address the web display listens on for -output web (use :8080 to allow other machines)")
+	screenshot       = flag.String("screenshot", "", "Fetch the current frame, save it to this file and exit (.png, .bmp or .raw; placeholders as in screenshot_path)")
+	recordSession    = flag.String("record", "", "Record every API request and response to this session archive (.tar)")
+	replaySession    = flag.String("replay", "", "Answer API requests from a recorded session archive instead of the server")
+	replaySpeed      = flag.Float64("replay-speed", 1, "How many times faster than recorded to replay a session")
+)
+
+// Display outputs (selectable via -output)
+const (
+	OutputWindow      = "window"
+	OutputTerminal    = "terminal"
+	OutputFramebuffer = "framebuffer"
+	OutputWeb         = "web"
 )
 
 // DisplayWindow interface for both Fyne and native windows
@@ -69,6 +100,16 @@ type DisplayWindow interface {
 	SetMenuItemsEnabled(bool)
 }
 
+// windowResizer is implemented by windows that can change size while running
+type windowResizer interface {
+	Resize(width, height int)
+}
+
+// windowSaver is implemented by windows with a save screenshot action (Cmd+S / Ctrl+S)
+type windowSaver interface {
+	SetOnSave(func())
+}
+
 type App struct {
 	config         *config.Config
 	client         *api.Client
@@ -78,10 +119,23 @@ type App struct {
 	doneCh         chan struct{}
 	refreshCh      chan struct{}
 	rotateCh       chan struct{}
+	saveCh         chan struct{}
+	exportCh       chan struct{}
+	exporting      atomic.Bool // Set while a timelapse export runs in the background
+	exportedCh     chan string // Status of a finished background export
+	navigateCh     chan int // History navigation actions (historyBack, ...)
+	reloadCh       chan struct{}
 	verbose        bool
 	needsSetup     bool
-	lastImageData  []byte // Store last fetched image for rotation without refresh
+	lastFrame      display.Frame // Last fetched image, kept for rotation without refresh and screenshots
+	liveStatus     string        // Status of the live display, restored after browsing the history
+	history        *history.History // Recently fetched frames (nil when disabled)
+	liveSeq        int              // History sequence number of lastFrame
+	viewed         history.Entry    // History frame being viewed (Seq 0 when showing the live display)
 	isConnected    bool   // Track if we've successfully connected
+	telemetry      *telemetry.Collector // Prometheus metrics (nil when disabled)
+	metrics        metrics.Provider     // Battery/WiFi metrics source shared by API clients
+	stopOnce       sync.Once            // Guards closing stopCh
 }
 
 // generateRandomMAC generates a random MAC address
@@ -101,8 +155,74 @@ func generateRandomMAC() string {
 		buf[0], buf[1], buf[2], buf[3], buf[4], buf[5])
 }
 
+// generateDeviceID returns a stable device ID derived from the machine ID, or a
+// random MAC address if the machine ID is unavailable, along with its source
+func generateDeviceID() (string, string) {
+	if mac, err := metrics.DeriveMACFromMachineID(); err == nil {
+		return mac, "machine-derived"
+	}
+	return generateRandomMAC(), "random"
+}
+
+// applyIdentitySeed derives the device ID from the identity seed (and the device
+// name in multi-device mode), replacing a saved ID derived from an earlier seed
+// The API key of a replaced ID belongs to the old identity, so the device registers again
+func applyIdentitySeed(cfg *config.Config) {
+	seed := cfg.IdentitySeed
+	if name := cfg.DeviceName(); name != "" {
+		seed += "/" + name
+	}
+	derived := metrics.DeriveMAC(seed)
+	if cfg.DeviceID == derived {
+		return
+	}
+
+	if cfg.DeviceID != "" && cfg.APIKey != "" {
+		log.Printf("Identity seed changed: device ID %s replaces %s, registering again", derived, cfg.DeviceID)
+		cfg.APIKey = ""
+		cfg.FriendlyID = ""
+		if !replaying() {
+			if err := cfg.SaveSetupInfo(); err != nil {
+				log.Printf("Warning: Could not save config: %v", err)
+			}
+		}
+	} else if cfg.Verbose {
+		log.Printf("Derived Device ID from identity seed: %s", derived)
+	}
+	cfg.DeviceID = derived
+	saveGeneratedDeviceID(cfg)
+}
+
+// saveGeneratedDeviceID persists a generated device ID immediately so the
+// server sees the same device on every launch
+func saveGeneratedDeviceID(cfg *config.Config) {
+	if replaying() {
+		return
+	}
+	if err := cfg.SaveDeviceID(); err != nil {
+		log.Printf("Warning: Could not save device ID: %v", err)
+	}
+}
+
+// usage prints the command-line flags followed by the supported environment variables
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: %s [flags]\n       %s config <command> [flags] [key] [value]\n       %s fetch [flags] [-out file]\n       %s timelapse [flags] [-out file.gif]\n\nFlags:\n", os.Args[0], os.Args[0], os.Args[0], os.Args[0])
+	flag.PrintDefaults()
+
+	fmt.Fprintln(out, "\nEnvironment variables (override the config file, overridden by flags; empty values are ignored):")
+	fmt.Fprintf(out, "  %-28s %s\n", config.ProfileEnvVar, "string  Configuration profile to use")
+	fmt.Fprintf(out, "  %-28s %s\n", config.CredentialPassphraseEnvVar, "string  Passphrase for the encrypted credential store")
+	for _, key := range config.Keys() {
+		if env := key.EnvVar(); env != "" {
+			fmt.Fprintf(out, "  %-28s %-7s Same as %q in config.json\n", env, key.TypeName(), key.Name)
+		}
+	}
+}
+
 // runGUIApp starts the GUI application
 func runGUIApp() {
+	flag.Usage = usage
 	flag.Parse()
 
 	// Show version
@@ -117,13 +237,200 @@ func runGUIApp() {
 		os.Exit(0)
 	}
 
-	// Load configuration
-	cfg, err := config.Load()
+	// Reset identity if requested
+	if *resetIdentity {
+		if err := config.ResetIdentity(*profile); err != nil {
+			log.Fatalf("Failed to reset identity: %v", err)
+		}
+		fmt.Println("Device identity reset - the device will register again on next run")
+		fmt.Println("A device ID read from the network interface or derived from the machine ID or identity_seed stays the same; change identity_seed for a new one")
+		os.Exit(0)
+	}
+
+	// Check configuration if requested
+	if *checkConfigFlag {
+		os.Exit(checkConfig())
+	}
+
+	// Load configuration (with the selected profile) and apply command-line flags
+	cfg, err := loadConfig()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	// Override config with command-line flags
+	// A manually specified MAC address clears the API key to force re-registration
+	// This allows testing with the same MAC across platforms
+	if *macAddress != "" {
+		cfg.APIKey = ""
+		if cfg.Verbose {
+			log.Printf("Using manually specified MAC address: %s (API key cleared for re-registration)", cfg.DeviceID)
+		}
+	}
+
+	// Check the configuration before using (or saving) it
+	if cfg.SchemaVersion > config.SchemaVersion {
+		log.Printf("Warning: Config file was written by a newer version of trmnl-go (schema %d, supported %d); settings will not be saved", cfg.SchemaVersion, config.SchemaVersion)
+	}
+	if unknown, err := config.UnknownKeys(); err == nil {
+		for _, key := range unknown {
+			log.Printf("Warning: Unknown config key %q (ignored)", key)
+		}
+	}
+	if err := cfg.ValidateSettings(); err != nil {
+		log.Fatalf("Invalid configuration: %v\nRun with -check-config for details", err)
+	}
+
+	// Move API keys still in plaintext into the credential store, if one is set
+	if !replaying() {
+		if moved, err := config.MoveCredentials(); err != nil {
+			log.Printf("Warning: Could not move API keys to the credential store: %v", err)
+		} else if moved && cfg.Verbose {
+			fmt.Println("[App] Moved plaintext API keys to the credential store")
+		}
+	}
+
+	// Save config if requested
+	if *saveConfig {
+		if err := cfg.Save(); err != nil {
+			log.Fatalf("Failed to save config: %v", err)
+		}
+		if cfg.Profile() != "" {
+			fmt.Printf("Configuration saved to profile %s\n", cfg.Profile())
+		} else {
+			fmt.Println("Configuration saved successfully")
+		}
+		os.Exit(0)
+	}
+
+	// Record or replay the API session if requested
+	finishSession, err := startSession(cfg.Verbose)
+	if err != nil {
+		log.Fatalf("Failed to start session: %v", err)
+	}
+	defer finishSession()
+
+	// Save the current frame and exit if requested
+	if *screenshot != "" {
+		code := runScreenshot(cfg)
+		finishSession()
+		os.Exit(code)
+	}
+
+	// Expand multi-device mode (a single entry when no devices are configured)
+	deviceConfigs, err := expandDevices(cfg)
+	if err != nil {
+		log.Fatalf("Invalid device configuration: %v", err)
+	}
+
+	// Start Prometheus metrics endpoint if enabled (shared by all devices)
+	var registry *telemetry.Registry
+	if cfg.MetricsAddr != "" {
+		registry = telemetry.NewRegistry()
+		if _, err := registry.Serve(cfg.MetricsAddr); err != nil {
+			log.Fatalf("Failed to start metrics endpoint: %v", err)
+		}
+		if cfg.Verbose {
+			fmt.Printf("[App] Metrics available at http://%s%s\n", cfg.MetricsAddr, telemetry.MetricsPath)
+		}
+	}
+
+	// Create one application (refresh loop) per device
+	apps := make([]*App, len(deviceConfigs))
+	for i, devCfg := range deviceConfigs {
+		apps[i] = newApp(devCfg, registry)
+	}
+
+	// Create display windows (platform-specific logic in app_darwin.go / app_other.go)
+	windows, err := createDisplays(deviceConfigs, cfg.Verbose)
+	if err != nil {
+		log.Fatalf("Failed to create display: %v", err)
+	}
+
+	// Set up signal handling for graceful shutdown
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+
+	// A terminal display draws where console output would go; silence it before
+	// the refresh loops start writing (fatal startup errors above stay visible)
+	restoreConsole := func() {}
+	if _, ok := windows[0].(*display.TerminalWindow); ok {
+		restoreConsole = display.QuietConsole()
+	}
+
+	for i, app := range apps {
+		app.window = windows[i]
+		app.connectWindow()
+
+		// Start refresh goroutine
+		go app.refreshLoop()
+	}
+
+	// Watch the config file and apply changes live
+	watcher, err := config.NewWatcher()
+	if err != nil {
+		log.Printf("Warning: Config hot-reload disabled: %v", err)
+	} else {
+		defer watcher.Close()
+		go func() {
+			for range watcher.Changes() {
+				for _, app := range apps {
+					app.requestReload()
+				}
+			}
+		}()
+	}
+
+	// Handle signals in goroutine
+	go func() {
+		<-sigCh
+		if cfg.Verbose {
+			fmt.Println("[App] Signal received, shutting down...")
+		}
+		for _, app := range apps {
+			app.stop()
+		}
+		for _, window := range windows {
+			window.Close()
+		}
+	}()
+
+	// Show windows (the first blocks until it is closed)
+	for _, window := range windows[1:] {
+		window.Show()
+	}
+	windows[0].Show()
+
+	// Closing the first window ends every device
+	for _, app := range apps {
+		app.stop()
+	}
+
+	// Wait for cleanup to complete
+	for _, app := range apps {
+		<-app.doneCh
+	}
+	restoreConsole()
+
+	if cfg.Verbose {
+		fmt.Println("[App] Shutdown complete")
+	}
+}
+
+// loadConfig loads the configuration with the selected profile and applies command-line flags
+// Used at startup and whenever the config file changes
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.LoadProfile(*profile)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyFlags(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// applyFlags overrides config values with command-line flags
+func applyFlags(cfg *config.Config) error {
 	if *apiKey != "" {
 		cfg.APIKey = *apiKey
 	}
@@ -131,22 +438,20 @@ func runGUIApp() {
 		cfg.DeviceID = *deviceID
 	}
 	if *macAddress != "" {
-		// MAC address flag overrides saved device ID and clears API key
-		// This allows testing with the same MAC across platforms
+		// MAC address flag overrides saved device ID (the API key is cleared at startup)
 		mac := strings.ToUpper(strings.TrimSpace(*macAddress))
 		if len(mac) == 17 && (strings.Count(mac, ":") == 5 || strings.Count(mac, "-") == 5) {
 			cfg.DeviceID = mac
-			cfg.APIKey = "" // Clear API key to force re-registration
-			if *verbose {
-				log.Printf("Using manually specified MAC address: %s (API key cleared for re-registration)", cfg.DeviceID)
-			}
 		} else {
-			log.Fatalf("Invalid MAC address format: %s (expected format: AA:BB:CC:DD:EE:FF or AA-BB-CC-DD-EE-FF)", *macAddress)
+			return fmt.Errorf("invalid MAC address format: %s (expected format: AA:BB:CC:DD:EE:FF or AA-BB-CC-DD-EE-FF)", *macAddress)
 		}
 	}
 	if *baseURL != "" {
 		cfg.BaseURL = *baseURL
 	}
+	if *identitySeed != "" {
+		cfg.IdentitySeed = *identitySeed
+	}
 
 	// Handle model selection
 	if *model != "" {
@@ -154,19 +459,7 @@ func runGUIApp() {
 	}
 
 	// Apply model defaults if model is set
-	if cfg.Model != "" {
-		deviceModel, err := models.GetModel(cfg.Model)
-		if err != nil {
-			log.Fatalf("Invalid model: %v\nUse -list-models to see available models", err)
-		}
-		// Set model dimensions as defaults (can be overridden by width/height flags)
-		if cfg.WindowWidth == config.DefaultWindowWidth {
-			cfg.WindowWidth = deviceModel.Width
-		}
-		if cfg.WindowHeight == config.DefaultWindowHeight {
-			cfg.WindowHeight = deviceModel.Height
-		}
-	}
+	applyModelDefaults(cfg)
 
 	// Override dimensions with explicit width/height flags
 	if *width > 0 {
@@ -212,57 +505,215 @@ func runGUIApp() {
 	if *logFlushInterval > 0 {
 		cfg.LogFlushInterval = *logFlushInterval
 	}
-
-	// Save config if requested
-	if *saveConfig {
-		if err := cfg.Save(); err != nil {
-			log.Fatalf("Failed to save config: %v", err)
+	if *metricsAddr != "" {
+		cfg.MetricsAddr = *metricsAddr
+	}
+	if *metricsProvider != "" {
+		cfg.MetricsProvider = *metricsProvider
+	}
+	if *metricsCommand != "" {
+		cfg.MetricsCommand = *metricsCommand
+	}
+	if *simulateBattery != "" || *simulateRSSI != "" {
+		if cfg.Simulation == nil {
+			cfg.Simulation = &metrics.SimulationProfile{}
 		}
-		fmt.Println("Configuration saved successfully")
-		os.Exit(0)
+		if *simulateBattery != "" {
+			cfg.Simulation.Battery = *simulateBattery
+		}
+		if *simulateRSSI != "" {
+			cfg.Simulation.RSSI = *simulateRSSI
+		}
+	}
+
+	return nil
+}
+
+// createFyneWindows creates Fyne windows for several devices sharing one app
+func createFyneWindows(cfgs []*config.Config, verbose bool) []DisplayWindow {
+	windows := make([]DisplayWindow, len(cfgs))
+	for i, window := range display.NewWindows(cfgs, verbose) {
+		windows[i] = window
 	}
+	return windows
+}
 
-	// Auto-detect MAC address as Device ID if not set
-	if cfg.DeviceID == "" && cfg.APIKey == "" {
-		mac, err := metrics.GetMACAddressForInterface(*netInterface)
+// createDisplays creates the output selected with -output, one per device
+func createDisplays(cfgs []*config.Config, verbose bool) ([]DisplayWindow, error) {
+	switch *output {
+	case OutputWindow:
+		return createWindows(cfgs, *useFyne, verbose), nil
+	case OutputTerminal:
+		if len(cfgs) > 1 {
+			return nil, fmt.Errorf("terminal output supports a single device (%d configured)", len(cfgs))
+		}
+		window, err := display.NewTerminalWindow(cfgs[0], *terminalGraphics, verbose)
 		if err != nil {
-			log.Printf("Warning: Could not detect MAC address: %v", err)
-			log.Println("Generating random MAC address instead")
-			cfg.DeviceID = generateRandomMAC()
-			if cfg.Verbose {
-				log.Printf("Generated random MAC address: %s", cfg.DeviceID)
-			}
+			return nil, err
+		}
+		return []DisplayWindow{window}, nil
+	case OutputFramebuffer:
+		if len(cfgs) > 1 {
+			return nil, fmt.Errorf("framebuffer output supports a single device (%d configured)", len(cfgs))
+		}
+		window, err := display.NewFramebufferWindow(cfgs[0], *framebuffer, *framebufferGeom, verbose)
+		if err != nil {
+			return nil, err
+		}
+		return []DisplayWindow{window}, nil
+	case OutputWeb:
+		if len(cfgs) > 1 {
+			return nil, fmt.Errorf("web output supports a single device (%d configured)", len(cfgs))
+		}
+		window, err := display.NewWebWindow(cfgs[0], *webAddr, verbose)
+		if err != nil {
+			return nil, err
+		}
+		return []DisplayWindow{window}, nil
+	}
+	return nil, fmt.Errorf("unknown output %q (expected window, terminal, framebuffer or web)", *output)
+}
+
+// applyModelDefaults sets the window size from the configured model,
+// unless the size was changed from the default
+// Unknown models are left to config validation, which reports them
+func applyModelDefaults(cfg *config.Config) {
+	if cfg.Model == "" {
+		return
+	}
+
+	deviceModel, err := models.GetModel(cfg.Model)
+	if err != nil {
+		return
+	}
+	// Set model dimensions as defaults (can be overridden by width/height flags)
+	if cfg.WindowWidth == config.DefaultWindowWidth {
+		cfg.WindowWidth = deviceModel.Width
+	}
+	if cfg.WindowHeight == config.DefaultWindowHeight {
+		cfg.WindowHeight = deviceModel.Height
+	}
+}
+
+// expandDevices returns one config per device, applying each device's own model
+func expandDevices(cfg *config.Config) ([]*config.Config, error) {
+	deviceConfigs, err := cfg.DeviceConfigs()
+	if err != nil {
+		return nil, err
+	}
+	for i, devCfg := range deviceConfigs {
+		if len(cfg.Devices) > 0 && cfg.Devices[i].Model != "" {
+			applyModelDefaults(devCfg)
+		}
+	}
+	return deviceConfigs, nil
+}
+
+// assignDeviceID detects or generates a device ID when neither a device ID
+// nor an API key is configured
+// An identity seed is applied on every start, so that changing it takes effect;
+// only -device-id and -mac-address take precedence over it
+func assignDeviceID(cfg *config.Config) {
+	if cfg.IdentitySeed != "" && *deviceID == "" && *macAddress == "" {
+		applyIdentitySeed(cfg)
+		return
+	}
+	if cfg.DeviceID != "" || cfg.APIKey != "" {
+		return
+	}
+
+	// Devices in multi-device mode can't share the network MAC, so each
+	// derives its own identity from the machine ID and its name
+	if name := cfg.DeviceName(); name != "" {
+		seed, _ := metrics.MachineID()
+		if seed != "" {
+			cfg.DeviceID = metrics.DeriveMAC(seed + "/" + name)
 		} else {
-			cfg.DeviceID = mac
-			if cfg.Verbose {
-				ifaceName := metrics.GetPrimaryInterfaceName()
-				if *netInterface != "" {
-					ifaceName = *netInterface
-				}
-				log.Printf("Auto-detected Device ID from %s: %s", ifaceName, mac)
+			cfg.DeviceID = generateRandomMAC()
+		}
+		log.Printf("Using generated device ID %s for %s", cfg.DeviceID, name)
+		saveGeneratedDeviceID(cfg)
+		return
+	}
+
+	// Auto-detect MAC address as Device ID
+	mac, err := metrics.GetMACAddressForInterface(*netInterface)
+	if err == nil && mac == "" {
+		err = fmt.Errorf("interface has no hardware address")
+	}
+	if err != nil {
+		log.Printf("Warning: Could not detect MAC address: %v", err)
+		var source string
+		cfg.DeviceID, source = generateDeviceID()
+		log.Printf("Using %s device ID %s", source, cfg.DeviceID)
+		saveGeneratedDeviceID(cfg)
+	} else {
+		cfg.DeviceID = mac
+		if cfg.Verbose {
+			ifaceName := metrics.GetPrimaryInterfaceName()
+			if *netInterface != "" {
+				ifaceName = *netInterface
 			}
+			log.Printf("Auto-detected Device ID from %s: %s", ifaceName, mac)
 		}
 	}
+}
+
+// newApp creates the application for one device
+// registry collects the device's Prometheus metrics (nil when disabled)
+func newApp(cfg *config.Config, registry *telemetry.Registry) *App {
+	assignDeviceID(cfg)
 
 	// Check if setup is needed (will be handled after GUI starts)
 	needsSetup := cfg.APIKey == "" || *setup
 
+	// Create the battery/WiFi metrics provider
+	metricsSource, err := newMetricsProvider(cfg, *netInterface)
+	if err != nil {
+		log.Fatalf("Invalid metrics provider: %v", err)
+	}
+
 	// Create application
 	app := &App{
 		config:     cfg,
-		client:     api.NewClient(cfg, cfg.Verbose),
-		logger:     logging.NewLogger(cfg.BaseURL, cfg.APIKey, cfg.Verbose),
+		metrics:    metricsSource,
 		stopCh:     make(chan struct{}),
 		doneCh:     make(chan struct{}),
 		refreshCh:  make(chan struct{}, 1), // Buffered to avoid blocking
 		rotateCh:   make(chan struct{}, 1), // Buffered to avoid blocking
+		saveCh:     make(chan struct{}, 1), // Buffered to avoid blocking
+		exportCh:   make(chan struct{}, 1), // Buffered to avoid blocking
+		exportedCh: make(chan string, 1),   // Buffered to avoid blocking
+		navigateCh: make(chan int, 8),      // Buffered so quick key presses aren't lost
+		reloadCh:   make(chan struct{}, 1), // Buffered to avoid blocking
 		verbose:    cfg.Verbose,
 		needsSetup: needsSetup,
 	}
 
+	// Record Prometheus metrics if enabled
+	if registry != nil {
+		app.telemetry = registry.Collector(cfg.DeviceName())
+	}
+
+	app.client = app.newClient()
+	app.logger = app.newLogger()
+	app.openHistory()
+
 	// Log startup
 	mac, _ := metrics.GetMACAddress()
-	m := metrics.Collect()
+	m := metrics.CollectFrom(app.metrics)
+	if sim, ok := app.metrics.(*metrics.Simulator); ok {
+		// Don't advance the simulation for the startup log
+		reading, _ := sim.Current()
+		m = reading.Metrics()
+	}
+
+	// Describe the wireless link (SSID, quality, bitrate) for diagnostics
+	var wifiDetails map[string]any
+	wifi, wifiErr := metrics.GetWiFiInfo(*netInterface)
+	if wifiErr == nil {
+		wifiDetails = wifi.Details()
+	}
 
 	if app.verbose {
 		if cfg.APIKey != "" {
@@ -283,11 +734,18 @@ func runGUIApp() {
 		"mac":        mac,
 		"battery":    m.BatteryVoltage,
 		"wifi_rssi":  m.RSSI,
+		"wifi":       wifiDetails,
 	})
 
 	// Print startup info
 	if app.verbose {
 		fmt.Printf("=== TRMNL Virtual Display v%s ===\n", Version)
+		if name := cfg.DeviceName(); name != "" {
+			fmt.Printf("Device: %s\n", name)
+		}
+		if cfg.Profile() != "" {
+			fmt.Printf("Profile: %s\n", cfg.Profile())
+		}
 		fmt.Printf("Base URL: %s\n", cfg.BaseURL)
 		if cfg.APIKey != "" {
 			fmt.Printf("Auth: API Key (%s)\n", config.RedactSensitive(cfg.APIKey))
@@ -308,91 +766,121 @@ func runGUIApp() {
 		fmt.Printf("Dark Mode: %v\n", cfg.DarkMode)
 		fmt.Printf("E-Paper Mode: %v\n", cfg.EPaperMode)
 		fmt.Printf("Mirror Mode: %v\n", cfg.MirrorMode)
-		batteryV := api.PercentageToVoltage(m.BatteryVoltage)
+		batteryV := api.ReportedVoltage(m, cfg.BatteryCurve)
 		fmt.Printf("System: Battery %.1f%% (%.2fV), WiFi %d dBm\n", m.BatteryVoltage, batteryV, m.RSSI)
+		if m.Charging != metrics.ChargingUnknown {
+			fmt.Printf("Charging: %s\n", m.Charging)
+		}
+		if wifiErr == nil {
+			fmt.Printf("WiFi: %s (via %s)\n", wifi, wifi.Source)
+		} else {
+			fmt.Printf("WiFi: unavailable (%v)\n", wifiErr)
+		}
 		fmt.Println("=====================================")
 	}
 
-	// Create display window (platform-specific logic in app_darwin.go / app_other.go)
-	app.window = createWindow(cfg, *useFyne, app.verbose)
-
-	// Set up signal handling for graceful shutdown
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	return app
+}
 
+// connectWindow wires the window's close, refresh and rotate actions to the app
+func (a *App) connectWindow() {
 	// Handle window close
-	app.window.SetOnClosed(func() {
-		if app.verbose {
+	a.window.SetOnClosed(func() {
+		if a.verbose {
 			fmt.Println("[App] Window closed, shutting down...")
 		}
-		close(app.stopCh)
+		a.stop()
 	})
 
 	// Handle refresh shortcut (Cmd+R / Ctrl+R)
-	app.window.SetOnRefresh(func() {
-		if !app.isConnected {
-			if app.verbose {
+	a.window.SetOnRefresh(func() {
+		if !a.isConnected {
+			if a.verbose {
 				fmt.Println("[App] Refresh ignored - not yet connected")
 			}
-			app.window.UpdateStatus("Please wait - connecting...")
+			a.window.UpdateStatus("Please wait - connecting...")
 			return
 		}
-		if app.verbose {
+		if a.verbose {
 			fmt.Println("[App] Manual refresh triggered")
 		}
 		// Non-blocking send to refresh channel
 		select {
-		case app.refreshCh <- struct{}{}:
+		case a.refreshCh <- struct{}{}:
 		default:
 			// Channel full, refresh already pending
 		}
 	})
 
 	// Handle rotate shortcut (Cmd+T / Ctrl+T)
-	app.window.SetOnRotate(func() {
-		if !app.isConnected {
-			if app.verbose {
+	a.window.SetOnRotate(func() {
+		if !a.isConnected {
+			if a.verbose {
 				fmt.Println("[App] Rotate ignored - not yet connected")
 			}
-			app.window.UpdateStatus("Please wait - connecting...")
+			a.window.UpdateStatus("Please wait - connecting...")
 			return
 		}
-		if app.verbose {
+		if a.verbose {
 			fmt.Println("[App] Manual rotate triggered")
 		}
 		// Non-blocking send to rotate channel
 		select {
-		case app.rotateCh <- struct{}{}:
+		case a.rotateCh <- struct{}{}:
 		default:
 			// Channel full, rotate already pending
 		}
 	})
 
-	// Disable menu items until connected
-	app.window.SetMenuItemsEnabled(false)
-
-	// Start refresh goroutine
-	go app.refreshLoop()
+	// Handle save screenshot shortcut (Cmd+S / Ctrl+S)
+	if saver, ok := a.window.(windowSaver); ok {
+		saver.SetOnSave(func() {
+			if !a.isConnected {
+				if a.verbose {
+					fmt.Println("[App] Save ignored - not yet connected")
+				}
+				a.window.UpdateStatus("Please wait - connecting...")
+				return
+			}
+			if a.verbose {
+				fmt.Println("[App] Save screenshot triggered")
+			}
+			// Non-blocking send to save channel
+			select {
+			case a.saveCh <- struct{}{}:
+			default:
+				// Channel full, save already pending
+			}
+		})
+	}
 
-	// Handle signals in goroutine
-	go func() {
-		<-sigCh
-		if app.verbose {
-			fmt.Println("[App] Signal received, shutting down...")
-		}
-		close(app.stopCh)
-		app.window.Close()
-	}()
+	// Handle export timelapse shortcut (Cmd+E / Ctrl+E)
+	if exporter, ok := a.window.(windowExporter); ok {
+		exporter.SetOnExport(func() {
+			if a.verbose {
+				fmt.Println("[App] Export timelapse triggered")
+			}
+			// Non-blocking send to export channel
+			select {
+			case a.exportCh <- struct{}{}:
+			default:
+				// Channel full, export already pending
+			}
+		})
+	}
 
-	// Show window (blocks until window is closed)
-	app.window.Show()
+	// Handle history navigation (back, forward and return to live)
+	a.connectNavigation()
 
-	// Wait for cleanup to complete
-	<-app.doneCh
+	// Disable menu items until connected
+	a.window.SetMenuItemsEnabled(false)
+}
 
-	if app.verbose {
-		fmt.Println("[App] Shutdown complete")
-	}
+// stop signals the refresh loop to exit (safe to call more than once)
+func (a *App) stop() {
+	a.stopOnce.Do(func() {
+		close(a.stopCh)
+	})
 }
 
 // refreshLoop continuously fetches and displays images
@@ -410,12 +898,13 @@ func (a *App) refreshLoop() {
 
 	// Handle setup if needed
 	if a.needsSetup {
-		a.window.UpdateStatus("Registering device...")
+		a.setStatus("Registering device...")
 		if a.verbose {
 			fmt.Println("[App] Running device setup/registration...")
 		}
 
 		setupResp, err := a.client.FetchSetup(a.config.DeviceID)
+		a.telemetry.ObserveFetch(api.SetupEndpoint, err)
 		if err != nil {
 			log.Printf("Setup failed: %v", err)
 			a.logger.Error("Device setup failed", map[string]any{
@@ -424,7 +913,7 @@ func (a *App) refreshLoop() {
 			})
 			a.logger.FlushOnError()
 			a.showErrorScreen("Registration Failed", fmt.Sprintf("Device: %s\nError: %v", a.config.DeviceID, err))
-			a.window.UpdateStatus("Registration failed - see display for details")
+			a.setStatus("Registration failed - see display for details")
 
 			// Keep window open with error displayed
 			// Wait for user to close window or signal
@@ -441,7 +930,12 @@ func (a *App) refreshLoop() {
 
 		// Save only the setup info (API key and friendly ID)
 		// This preserves any other settings from flags without persisting them
-		if err := a.config.SaveSetupInfo(); err != nil {
+		// A replayed session's redacted key is never saved
+		if replaying() {
+			if a.verbose {
+				fmt.Println("[App] Replaying a recorded session, setup info not saved")
+			}
+		} else if err := a.config.SaveSetupInfo(); err != nil {
 			log.Printf("Warning: Could not save config: %v", err)
 			a.logger.Warn("Failed to save config after setup", map[string]any{
 				"error": err.Error(),
@@ -449,7 +943,7 @@ func (a *App) refreshLoop() {
 		}
 
 		// Update client with new API key
-		a.client = api.NewClient(a.config, a.verbose)
+		a.client = a.newClient()
 
 		if a.verbose {
 			fmt.Printf("[App] Setup successful! Device registered as: %s\n", a.config.FriendlyID)
@@ -460,12 +954,12 @@ func (a *App) refreshLoop() {
 			"device_id":   a.config.DeviceID,
 		})
 
-		a.window.UpdateStatus(fmt.Sprintf("Registered as %s", a.config.FriendlyID))
+		a.setStatus(fmt.Sprintf("Registered as %s", a.config.FriendlyID))
 		time.Sleep(SuccessMessageDelay) // Show success message briefly
 	}
 
 	// Initial status
-	a.window.UpdateStatus("Connecting to TRMNL API...")
+	a.setStatus("Connecting to TRMNL API...")
 
 	// Fetch and display first image
 	refreshRate := a.fetchAndDisplay()
@@ -517,6 +1011,36 @@ func (a *App) refreshLoop() {
 			// Re-render current image with new rotation (don't fetch new image)
 			a.reRenderCurrentImage()
 
+		case <-a.saveCh:
+			// Save screenshot triggered by keyboard shortcut or menu
+			a.saveScreenshot()
+
+		case <-a.exportCh:
+			// Export timelapse triggered by keyboard shortcut or menu
+			a.exportTimelapse()
+
+		case status := <-a.exportedCh:
+			a.setStatus(status)
+
+		case action := <-a.navigateCh:
+			// History navigation triggered by keyboard shortcut or menu
+			a.navigateHistory(action)
+
+		case <-a.reloadCh:
+			// Config file changed
+			refetch := a.reloadConfig()
+			if interval := time.Duration(a.config.LogFlushInterval) * time.Second; interval != flushInterval {
+				flushInterval = interval
+				logFlushTicker.Reset(flushInterval)
+				if a.verbose {
+					fmt.Printf("[App] Log flush interval: %v\n", flushInterval)
+				}
+			}
+			if refetch {
+				refreshRate = a.fetchAndDisplay()
+				ticker.Reset(time.Duration(refreshRate) * time.Second)
+			}
+
 		case <-logFlushTicker.C:
 			// Periodically flush logs to API (successful operations)
 			if err := a.logger.Flush(); err != nil && a.verbose {
@@ -526,6 +1050,174 @@ func (a *App) refreshLoop() {
 	}
 }
 
+// newClient creates an API client for the current config and metrics provider
+func (a *App) newClient() *api.Client {
+	return api.NewClient(a.config, a.metrics, a.verbose)
+}
+
+// newLogger creates an API logger for the current config
+// Loggers share the API clients' transport and label console output per device
+func (a *App) newLogger() *logging.Logger {
+	logger := logging.NewLogger(a.config.BaseURL, a.config.APIKey, a.verbose)
+	logger.SetTransport(api.SharedTransport())
+	logger.SetName(a.config.DeviceName())
+	if a.telemetry != nil {
+		logger.SetOnFlush(a.telemetry.ObserveLogFlush)
+	}
+	return logger
+}
+
+// requestReload asks the refresh loop to re-read the config file
+func (a *App) requestReload() {
+	// Non-blocking send to reload channel
+	select {
+	case a.reloadCh <- struct{}{}:
+	default:
+		// Channel full, reload already pending
+	}
+}
+
+// reloadConfig re-reads the config file and applies changes to the running display
+// Returns true if the display must be fetched again (mirror mode, server or credentials changed)
+// An invalid config is reported on the status bar and the current settings are kept
+func (a *App) reloadConfig() bool {
+	cfg, err := a.loadReloadedConfig()
+	if err != nil {
+		log.Printf("Config reload failed: %v", err)
+		a.logger.Warn("Config reload failed", map[string]any{
+			"error": err.Error(),
+		})
+		a.setStatus(fmt.Sprintf("Config error: %v", err))
+		return false
+	}
+
+	if a.config.SameSettings(cfg) {
+		// Nothing changed (e.g. the app saved its own rotation)
+		return false
+	}
+
+	old := *a.config
+	*a.config = *cfg
+
+	if a.verbose {
+		fmt.Println("[App] Config file changed, applying...")
+	}
+
+	refetch := cfg.MirrorMode != old.MirrorMode
+
+	// A different server or identity needs a new client and logger
+	if cfg.BaseURL != old.BaseURL || cfg.APIKey != old.APIKey || cfg.DeviceID != old.DeviceID {
+		// Send pending logs with the old credentials first
+		if err := a.logger.Flush(); err != nil && a.verbose {
+			fmt.Printf("[App] Failed to flush logs before reload: %v\n", err)
+		}
+		a.client = a.newClient()
+		a.logger = a.newLogger()
+		refetch = true
+	}
+
+	if cfg.WindowWidth != old.WindowWidth || cfg.WindowHeight != old.WindowHeight {
+		if resizer, ok := a.window.(windowResizer); ok {
+			resizer.Resize(cfg.WindowWidth, cfg.WindowHeight)
+		} else if a.verbose {
+			fmt.Println("[App] Window size change requires a restart")
+		}
+	}
+
+	if !refetch && (cfg.Rotation != old.Rotation || cfg.DarkMode != old.DarkMode || cfg.EPaperMode != old.EPaperMode) {
+		a.reRenderCurrentImage()
+	}
+
+	a.logger.Info("Configuration reloaded", nil)
+	a.setStatus("Configuration reloaded")
+
+	return refetch
+}
+
+// loadReloadedConfig loads and validates the config for this device after the file changed
+func (a *App) loadReloadedConfig() (*config.Config, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	deviceConfigs, err := expandDevices(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var devCfg *config.Config
+	for _, c := range deviceConfigs {
+		if c.DeviceName() == a.config.DeviceName() {
+			devCfg = c
+			break
+		}
+	}
+	if devCfg == nil {
+		return nil, fmt.Errorf("device %s is no longer in the config file", a.config.DeviceName())
+	}
+
+	// Keep the device ID detected at startup (auto-detected MACs are not saved)
+	if devCfg.DeviceID == "" {
+		devCfg.DeviceID = a.config.DeviceID
+	}
+
+	// With -mac-address the API key saved for another identity was cleared at
+	// startup; keep using the one registered during this run (if any)
+	if *macAddress != "" {
+		devCfg.APIKey = a.config.APIKey
+		devCfg.FriendlyID = a.config.FriendlyID
+	}
+
+	if err := devCfg.Validate(); err != nil {
+		return nil, err
+	}
+	return devCfg, nil
+}
+
+// newMetricsProvider creates the metrics provider selected in the config
+// Without an explicit provider, a configured simulation profile selects the simulator
+// ifaceName selects the wireless interface for host RSSI readings ("" selects automatically)
+func newMetricsProvider(cfg *config.Config, ifaceName string) (metrics.Provider, error) {
+	kind := cfg.MetricsProvider
+	if kind == "" {
+		kind = metrics.ProviderHost
+		if cfg.Simulation != nil && (cfg.Simulation.Battery != "" || cfg.Simulation.RSSI != "") {
+			kind = metrics.ProviderSimulated
+		}
+	}
+
+	if cfg.Verbose {
+		log.Printf("Using %s metrics provider", kind)
+	}
+
+	switch kind {
+	case metrics.ProviderHost:
+		return metrics.HostProvider{Interface: ifaceName}, nil
+
+	case metrics.ProviderStatic:
+		if cfg.StaticMetrics == nil {
+			return nil, fmt.Errorf("static provider requires static_metrics in config")
+		}
+		return metrics.NewStaticProvider(*cfg.StaticMetrics), nil
+
+	case metrics.ProviderSimulated:
+		if cfg.Simulation == nil {
+			return nil, fmt.Errorf("simulated provider requires a simulation profile")
+		}
+		sim, err := metrics.NewSimulator(*cfg.Simulation)
+		if err != nil {
+			return nil, err
+		}
+		sim.SetFallback(metrics.HostProvider{Interface: ifaceName})
+		return sim, nil
+
+	case metrics.ProviderCommand:
+		return metrics.NewCommandProvider(cfg.MetricsCommand)
+	}
+
+	return nil, fmt.Errorf("unknown metrics provider: %s", kind)
+}
+
 // showStartupScreen displays a startup/splash screen
 func (a *App) showStartupScreen() {
 	if a.verbose {
@@ -568,10 +1260,14 @@ func (a *App) showStartupScreen() {
 }
 
 // showErrorScreen displays an error message on screen
+// While browsing the history, the frame being viewed is left on screen
 func (a *App) showErrorScreen(title, message string) {
 	if a.verbose {
 		fmt.Printf("[App] Showing error screen: %s - %s\n", title, message)
 	}
+	if a.viewingHistory() {
+		return
+	}
 
 	errorImg, err := render.GenerateErrorScreen(
 		a.config.WindowWidth,
@@ -591,7 +1287,8 @@ func (a *App) showErrorScreen(title, message string) {
 
 // reRenderCurrentImage re-renders the last fetched image with current rotation/dark mode settings
 func (a *App) reRenderCurrentImage() {
-	if a.lastImageData == nil {
+	frame := a.shownFrame()
+	if frame.Data == nil {
 		if a.verbose {
 			fmt.Println("[App] No image data to re-render")
 		}
@@ -603,7 +1300,7 @@ func (a *App) reRenderCurrentImage() {
 	}
 
 	// Update display with stored image data (rotation/dark mode applied in UpdateImage)
-	if err := a.window.UpdateImage(a.lastImageData); err != nil {
+	if err := a.window.UpdateImage(frame.Data); err != nil {
 		log.Printf("Failed to re-render image: %v", err)
 		a.window.UpdateStatus(fmt.Sprintf("Error re-rendering: %v", err))
 	}
@@ -630,8 +1327,10 @@ func (a *App) rotateDisplay() {
 	}
 
 	// Save only the rotation setting (preserves other temporary flag settings)
-	if err := a.config.SaveRotation(); err != nil && a.verbose {
-		fmt.Printf("[App] Warning: Failed to save rotation to config: %v\n", err)
+	if !replaying() {
+		if err := a.config.SaveRotation(); err != nil && a.verbose {
+			fmt.Printf("[App] Warning: Failed to save rotation to config: %v\n", err)
+		}
 	}
 
 	a.logger.Info("Display rotation changed", map[string]any{
@@ -656,9 +1355,12 @@ func (a *App) fetchAndDisplay() int {
 
 	if a.config.MirrorMode {
 		termResp, err = a.client.FetchCurrentScreen()
+		a.telemetry.ObserveFetch(api.CurrentScreenEndpoint, err)
 	} else {
 		termResp, err = a.client.FetchDisplay()
+		a.telemetry.ObserveFetch(api.DisplayEndpoint, err)
 	}
+	a.telemetry.SetSystemMetrics(a.client.LastMetrics())
 
 	if err != nil {
 		log.Printf("Failed to fetch display: %v", err)
@@ -667,7 +1369,7 @@ func (a *App) fetchAndDisplay() int {
 			"mirror_mode": a.config.MirrorMode,
 		})
 		a.logger.FlushOnError() // Send logs on error
-		a.window.UpdateStatus(fmt.Sprintf("Error: %v", err))
+		a.setStatus(fmt.Sprintf("Error: %v", err))
 		a.showErrorScreen("Connection Error", fmt.Sprintf("Failed to connect to server: %v", err))
 		return 60 // Retry in 60 seconds
 	}
@@ -680,13 +1382,15 @@ func (a *App) fetchAndDisplay() int {
 			"status": termResp.Status,
 		})
 		a.logger.FlushOnError() // Send logs on error
-		a.window.UpdateStatus(fmt.Sprintf("API Error: %s", termResp.Error))
+		a.setStatus(fmt.Sprintf("API Error: %s", termResp.Error))
 		a.showErrorScreen("API Error", termResp.Error)
 		return 60 // Retry in 60 seconds
 	}
 
 	// Download image
+	downloadStart := time.Now()
 	imageData, err := a.client.FetchImage(termResp.ImageURL)
+	a.telemetry.ObserveFetch(telemetry.ImageEndpoint, err)
 	if err != nil {
 		log.Printf("Failed to fetch image: %v", err)
 		a.logger.Error("Failed to download image", map[string]any{
@@ -694,22 +1398,35 @@ func (a *App) fetchAndDisplay() int {
 			"image_url": termResp.ImageURL,
 		})
 		a.logger.FlushOnError() // Send logs on error
-		a.window.UpdateStatus(fmt.Sprintf("Error downloading image: %v", err))
+		a.setStatus(fmt.Sprintf("Error downloading image: %v", err))
 		a.showErrorScreen("Download Error", fmt.Sprintf("Could not download image: %v", err))
 		return termResp.RefreshRate
 	}
 
-	// Store image data for rotation without refresh
-	a.lastImageData = imageData
+	a.telemetry.ObserveImageDownload(len(imageData), time.Since(downloadStart))
 
-	// Update display
-	if err := a.window.UpdateImage(imageData); err != nil {
+	// Store image data for rotation without refresh and for screenshots
+	a.lastFrame = display.Frame{
+		Data:       imageData,
+		Filename:   termResp.Filename,
+		FriendlyID: a.config.FriendlyID,
+		Time:       time.Now(),
+	}
+	a.recordFrame(a.lastFrame, termResp.RefreshRate)
+
+	// Update display (unless a history frame is being viewed)
+	if !a.viewingHistory() {
+		renderStart := time.Now()
+		err = a.window.UpdateImage(imageData)
+		a.telemetry.ObserveRender(time.Since(renderStart))
+	}
+	if err != nil {
 		log.Printf("Failed to update display: %v", err)
 		a.logger.Error("Failed to render image", map[string]any{
 			"error": err.Error(),
 		})
 		a.logger.FlushOnError() // Send logs on error
-		a.window.UpdateStatus(fmt.Sprintf("Error displaying image: %v", err))
+		a.setStatus(fmt.Sprintf("Error displaying image: %v", err))
 		a.showErrorScreen("Display Error", fmt.Sprintf("Could not render image: %v", err))
 		return termResp.RefreshRate
 	}
@@ -734,7 +1451,10 @@ func (a *App) fetchAndDisplay() int {
 		statusMsg = "[Mirror] " + statusMsg
 	}
 
-	a.window.UpdateStatus(statusMsg)
+	a.setStatus(statusMsg)
+
+	a.telemetry.SetRefreshRate(termResp.RefreshRate)
+	a.telemetry.MarkSuccess(time.Now())
 
 	if a.verbose {
 		fmt.Printf("[App] Display updated. Next refresh in %d seconds\n", termResp.RefreshRate)
@@ -20,3 +20,15 @@ func createWindow(cfg *config.Config, useFyne bool, verbose bool) DisplayWindow
 	}
 	return display.NewWindow(cfg, verbose)
 }
+
+// createWindows creates one window per device
+// Several devices always use Fyne windows, as the native window supports only one
+func createWindows(cfgs []*config.Config, useFyne bool, verbose bool) []DisplayWindow {
+	if len(cfgs) == 1 {
+		return []DisplayWindow{createWindow(cfgs[0], useFyne, verbose)}
+	}
+	if verbose {
+		println("[App] Using Fyne windows for multiple devices")
+	}
+	return createFyneWindows(cfgs, verbose)
+}
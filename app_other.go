@@ -12,3 +12,11 @@ func createWindow(cfg *config.Config, useFyne bool, verbose bool) DisplayWindow
 	// On non-macOS platforms, always use Fyne
 	return display.NewWindow(cfg, verbose)
 }
+
+// createWindows creates one window per device
+func createWindows(cfgs []*config.Config, useFyne bool, verbose bool) []DisplayWindow {
+	if len(cfgs) == 1 {
+		return []DisplayWindow{createWindow(cfgs[0], useFyne, verbose)}
+	}
+	return createFyneWindows(cfgs, verbose)
+}
@@ -5,20 +5,36 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/semaja2/trmnl-go/metrics"
 )
 
 // Config holds the application configuration
 type Config struct {
+	// SchemaVersion is the file format version, managed automatically (see SchemaVersion)
+	SchemaVersion int `json:"schema_version,omitempty"`
+
 	// APIKey for usetrmnl.com authentication (if using cloud service)
 	APIKey string `json:"api_key,omitempty"`
 
-	// DeviceID for self-hosted server authentication (MAC address or unique ID)
+	// CredentialStore keeps API keys out of this file: plaintext (default), file
+	// (encrypted credentials.enc next to this file) or command (credential_command)
+	CredentialStore string `json:"credential_store,omitempty"`
+
+	// CredentialCommand is the secret helper run by the command credential store
+	CredentialCommand string `json:"credential_command,omitempty"`
+
+	// DeviceID for self-hosted server authentication (a MAC address, AA:BB:CC:DD:EE:FF)
 	// If not set, will auto-detect primary network interface MAC address
 	DeviceID string `json:"device_id,omitempty"`
 
 	// FriendlyID is the human-readable device name from setup
 	FriendlyID string `json:"friendly_id,omitempty"`
 
+	// IdentitySeed derives a stable device ID (locally administered MAC) instead of
+	// using the detected network interface MAC
+	IdentitySeed string `json:"identity_seed,omitempty"`
+
 	// BaseURL for the TRMNL API (default: https://trmnl.app)
 	BaseURL string `json:"base_url,omitempty"`
 
@@ -55,6 +71,80 @@ type Config struct {
 	// LogFlushInterval sets how often logs are flushed to API (in seconds)
 	// Default: 1800 (30 minutes). Set to lower value for development (e.g., 60)
 	LogFlushInterval int `json:"log_flush_interval,omitempty"`
+
+	// MetricsAddr enables a Prometheus /metrics endpoint on this address (e.g. ":9100")
+	// Empty disables the endpoint
+	MetricsAddr string `json:"metrics_addr,omitempty"`
+
+	// MetricsProvider selects where battery/WiFi metrics come from:
+	// host (default), static, simulated or command
+	MetricsProvider string `json:"metrics_provider,omitempty"`
+
+	// MetricsCommand is run by the command provider; it must print a JSON reading
+	MetricsCommand string `json:"metrics_command,omitempty"`
+
+	// StaticMetrics are the fixed values reported by the static provider
+	StaticMetrics *metrics.ReadingSpec `json:"static_metrics,omitempty"`
+
+	// BatteryCurve overrides the percentage-to-voltage table used when the
+	// host doesn't report a measured battery voltage
+	BatteryCurve metrics.VoltageCurve `json:"battery_curve,omitempty"`
+
+	// Simulation replaces host battery/WiFi readings with scripted profiles
+	// Useful for testing server-side low-battery plugins and alerts
+	Simulation *metrics.SimulationProfile `json:"simulation,omitempty"`
+
+	// ScreenshotPath is the filename template for saved screenshots
+	// Placeholders: {timestamp}, {friendly_id}, {filename} and {ext}
+	ScreenshotPath string `json:"screenshot_path,omitempty"`
+
+	// ScreenshotFormat is png (default), bmp (1-bit) or raw (bytes as downloaded),
+	// used when the screenshot path's extension doesn't select one
+	ScreenshotFormat string `json:"screenshot_format,omitempty"`
+
+	// HistorySize is how many recent frames are kept (on disk) for browsing back
+	// Default: 100. Set to 0 to disable the history
+	HistorySize int `json:"history_size"`
+
+	// Devices runs several virtual displays in one process, one window each
+	// Every device has its own credentials; its other empty fields inherit the settings above
+	Devices []Device `json:"devices,omitempty"`
+
+	// DefaultProfile is the profile used when none is selected with -profile or TRMNL_PROFILE
+	DefaultProfile string `json:"default_profile,omitempty"`
+
+	// Profiles holds named overrides applied on top of the settings above
+	// e.g. {"staging": {"base_url": "https://staging.example.com", "api_key": "..."}}
+	Profiles map[string]json.RawMessage `json:"profiles,omitempty"`
+
+	// device is the name of the Devices entry this config was expanded from
+	device string
+
+	// profile is the name of the active profile
+	profile string
+
+	// baseline holds the values as loaded, so Save only writes what changed since
+	baseline map[string]json.RawMessage
+}
+
+// Device is one virtual display in multi-device mode
+type Device struct {
+	// Name identifies the device in window titles, logs and metrics (required, unique)
+	Name string `json:"name"`
+
+	// APIKey, DeviceID and FriendlyID are never inherited, so each device registers separately
+	APIKey     string `json:"api_key,omitempty"`
+	DeviceID   string `json:"device_id,omitempty"`
+	FriendlyID string `json:"friendly_id,omitempty"`
+
+	BaseURL      string `json:"base_url,omitempty"`
+	Model        string `json:"model,omitempty"`
+	WindowWidth  int    `json:"window_width,omitempty"`
+	WindowHeight int    `json:"window_height,omitempty"`
+	Rotation     *int   `json:"rotation,omitempty"`
+	DarkMode     *bool  `json:"dark_mode,omitempty"`
+	EPaperMode   *bool  `json:"epaper_mode,omitempty"`
+	MirrorMode   *bool  `json:"mirror_mode,omitempty"`
 }
 
 const (
@@ -62,49 +152,169 @@ const (
 	DefaultWindowWidth     = 800
 	DefaultWindowHeight    = 480
 	DefaultLogFlushInterval = 1800 // 30 minutes
+	DefaultHistorySize     = 100
 	ConfigFileName         = "config.json"
 )
 
-// Load reads configuration from file and environment variables
-// Priority: CLI flags > Environment variables > Config file > Defaults
-func Load() (*Config, error) {
-	cfg := &Config{
+// defaultConfig returns the configuration used when nothing else is set
+func defaultConfig() *Config {
+	return &Config{
 		BaseURL:          DefaultBaseURL,
 		WindowWidth:      DefaultWindowWidth,
 		WindowHeight:     DefaultWindowHeight,
 		LogFlushInterval: DefaultLogFlushInterval,
+		HistorySize:      DefaultHistorySize,
 	}
+}
 
-	// Get config directory path
-	configDir, err := getConfigDir()
+// Load reads configuration from file and environment variables, applying the
+// profile selected by TRMNL_PROFILE or the file's default profile
+// Priority: CLI flags > Environment variables > Profile > Config file > Defaults
+func Load() (*Config, error) {
+	return LoadProfile("")
+}
+
+// LoadProfile reads configuration with the named profile applied over the shared base
+// An empty name selects TRMNL_PROFILE or the file's default profile, if any
+func LoadProfile(name string) (*Config, error) {
+	cfg, err := readFile()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get config directory: %w", err)
+		return nil, err
+	}
+
+	// Apply the selected profile's overrides
+	if name = cfg.resolveProfile(name); name != "" {
+		if err := cfg.applyProfile(name); err != nil {
+			return nil, err
+		}
+	}
+
+	// Override with environment variables (TRMNL_<KEY>, e.g. TRMNL_DARK_MODE)
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+
+	if cfg.baseline, err = configValues(cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// readFile reads the shared base configuration from file, without profiles or
+// environment variables applied
+func readFile() (*Config, error) {
+	cfg := defaultConfig()
+
+	// Get config file path
+	configPath, err := Path()
+	if err != nil {
+		return nil, err
 	}
 
 	// Read from config file if it exists
-	configPath := filepath.Join(configDir, ConfigFileName)
 	if data, err := os.ReadFile(configPath); err == nil {
+		if data, err = decodeConfigFile(data); err != nil {
+			return nil, err
+		}
 		if err := json.Unmarshal(data, cfg); err != nil {
 			return nil, fmt.Errorf("failed to parse config file: %w", err)
 		}
 	}
 
-	// Override with environment variables
-	if apiKey := os.Getenv("TRMNL_API_KEY"); apiKey != "" {
-		cfg.APIKey = apiKey
+	return cfg, nil
+}
+
+// Save writes the settings changed since the config was loaded to disk
+// The changes are merged into the current file under a lock, so concurrent updates
+// to other settings (by another instance or the config command) are preserved
+// With a profile active, they are written as that profile's overrides
+func (c *Config) Save() error {
+	values, err := configValues(c)
+	if err != nil {
+		return err
+	}
+	baseline := c.baseline
+	if baseline == nil {
+		if baseline, err = configValues(defaultConfig()); err != nil {
+			return err
+		}
+	}
+
+	var changed []string
+	for _, key := range unionKeys(baseline, values) {
+		if key != schemaVersionKey && !jsonEqual(baseline[key], values[key]) {
+			changed = append(changed, key)
+		}
 	}
-	if deviceID := os.Getenv("TRMNL_DEVICE_ID"); deviceID != "" {
-		cfg.DeviceID = deviceID
+	if len(changed) == 0 {
+		return nil
 	}
-	if baseURL := os.Getenv("TRMNL_BASE_URL"); baseURL != "" {
-		cfg.BaseURL = baseURL
+
+	_, err = updateDocument(c.profile, func(target, base map[string]json.RawMessage) error {
+		for _, key := range changed {
+			doc := target
+			if baseOnlyKeys[key] {
+				doc = base
+			}
+
+			// A profile override starts from the value it inherits
+			current, ok := doc[key]
+			if !ok {
+				current = base[key]
+			}
+			merged, err := mergeValue(baseline[key], values[key], current)
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case merged != nil:
+				doc[key] = merged
+			case !ok && base[key] != nil:
+				// Cleared in a profile: override the inherited value
+				doc[key] = zeroValueJSON(key)
+			default:
+				delete(doc, key)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	return cfg, nil
+	c.baseline = values
+	return nil
 }
 
-// Save writes the entire configuration to disk
-func (c *Config) Save() error {
+// decodeConfigFile prepares raw config file contents for use: older schema versions
+// are migrated and API keys are filled from the credential store, in memory only
+func decodeConfigFile(data []byte) ([]byte, error) {
+	data, err := migrateData(data)
+	if err != nil {
+		return nil, err
+	}
+	return unsealCredentials(data)
+}
+
+// writeConfigFile replaces the config file contents, moving API keys into the
+// credential store if one is configured
+func writeConfigFile(data []byte) error {
+	configPath, err := Path()
+	if err != nil {
+		return err
+	}
+	previous, _ := os.ReadFile(configPath) // Empty for a new file
+	data, err = sealCredentials(data, previous)
+	if err != nil {
+		return err
+	}
+	return writeConfigData(data)
+}
+
+// writeConfigData replaces the config file contents as given, creating the directory if needed
+func writeConfigData(data []byte) error {
 	configDir, err := getConfigDir()
 	if err != nil {
 		return fmt.Errorf("failed to get config directory: %w", err)
@@ -116,30 +326,132 @@ func (c *Config) Save() error {
 	}
 
 	configPath := filepath.Join(configDir, ConfigFileName)
-	data, err := json.MarshalIndent(c, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+	if err := checkWritable(configPath); err != nil {
+		return err
 	}
-
-	if err := os.WriteFile(configPath, data, 0600); err != nil {
+	if err := writeFileAtomic(configPath, data, 0600); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
 	return nil
 }
 
+// Path returns the location of the config file
+func Path() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return filepath.Join(configDir, ConfigFileName), nil
+}
+
+// DeviceConfigs expands multi-device mode into one Config per device
+// Without devices, the config itself is returned as the only entry
+func (c *Config) DeviceConfigs() ([]*Config, error) {
+	if len(c.Devices) == 0 {
+		return []*Config{c}, nil
+	}
+
+	seen := make(map[string]bool)
+	configs := make([]*Config, 0, len(c.Devices))
+	for i, d := range c.Devices {
+		if d.Name == "" {
+			return nil, fmt.Errorf("device %d has no name", i+1)
+		}
+		if seen[d.Name] {
+			return nil, fmt.Errorf("duplicate device name: %s", d.Name)
+		}
+		seen[d.Name] = true
+
+		configs = append(configs, c.forDevice(d))
+	}
+
+	return configs, nil
+}
+
+// forDevice returns a copy of the config with the device's settings applied
+func (c *Config) forDevice(d Device) *Config {
+	dc := *c
+	dc.Devices = nil
+	dc.device = d.Name
+
+	dc.APIKey = d.APIKey
+	dc.DeviceID = d.DeviceID
+	dc.FriendlyID = d.FriendlyID
+
+	if d.BaseURL != "" {
+		dc.BaseURL = d.BaseURL
+	}
+	if d.Model != "" {
+		dc.Model = d.Model
+		// Let the device's model pick the window size unless one is given
+		dc.WindowWidth = DefaultWindowWidth
+		dc.WindowHeight = DefaultWindowHeight
+	}
+	if d.WindowWidth > 0 {
+		dc.WindowWidth = d.WindowWidth
+	}
+	if d.WindowHeight > 0 {
+		dc.WindowHeight = d.WindowHeight
+	}
+	if d.Rotation != nil {
+		dc.Rotation = *d.Rotation
+	}
+	if d.DarkMode != nil {
+		dc.DarkMode = *d.DarkMode
+	}
+	if d.EPaperMode != nil {
+		dc.EPaperMode = *d.EPaperMode
+	}
+	if d.MirrorMode != nil {
+		dc.MirrorMode = *d.MirrorMode
+	}
+
+	return &dc
+}
+
+// DeviceName returns the name of the device this config was expanded from
+// Empty outside multi-device mode
+func (c *Config) DeviceName() string {
+	return c.device
+}
+
+// loadForSave loads the config file for a partial save
+// For a config expanded from a device, the matching device entry is also returned
+func (c *Config) loadForSave() (*Config, *Device, error) {
+	// Load current config (and active profile) from disk
+	savedConfig, err := LoadProfile(c.profile)
+	if err != nil {
+		// If config doesn't exist, create a new one
+		savedConfig = c
+	}
+
+	if c.device == "" {
+		return savedConfig, nil, nil
+	}
+	for i := range savedConfig.Devices {
+		if savedConfig.Devices[i].Name == c.device {
+			return savedConfig, &savedConfig.Devices[i], nil
+		}
+	}
+	return nil, nil, fmt.Errorf("device %s not found in config file", c.device)
+}
+
 // SaveRotation saves only the rotation setting to the config file
 // This preserves other settings that may have been set temporarily via flags
 func (c *Config) SaveRotation() error {
-	// Load current config from disk
-	savedConfig, err := Load()
+	savedConfig, device, err := c.loadForSave()
 	if err != nil {
-		// If config doesn't exist, create a new one
-		savedConfig = c
+		return err
 	}
 
 	// Update only rotation
-	savedConfig.Rotation = c.Rotation
+	if device != nil {
+		rotation := c.Rotation
+		device.Rotation = &rotation
+	} else {
+		savedConfig.Rotation = c.Rotation
+	}
 
 	// Save back
 	return savedConfig.Save()
@@ -148,21 +460,67 @@ func (c *Config) SaveRotation() error {
 // SaveSetupInfo saves only the API key and friendly ID to the config file
 // Used after device registration to persist authentication without saving temporary flags
 func (c *Config) SaveSetupInfo() error {
-	// Load current config from disk
-	savedConfig, err := Load()
+	savedConfig, device, err := c.loadForSave()
 	if err != nil {
-		// If config doesn't exist, create a new one
-		savedConfig = c
+		return err
 	}
 
 	// Update only setup-related fields
-	savedConfig.APIKey = c.APIKey
-	savedConfig.FriendlyID = c.FriendlyID
+	if device != nil {
+		device.APIKey = c.APIKey
+		device.FriendlyID = c.FriendlyID
+	} else {
+		savedConfig.APIKey = c.APIKey
+		savedConfig.FriendlyID = c.FriendlyID
+	}
+
+	// Save back
+	return savedConfig.Save()
+}
+
+// SaveDeviceID saves only the device ID to the config file
+// Used to persist a generated identity immediately so it survives restarts
+func (c *Config) SaveDeviceID() error {
+	savedConfig, device, err := c.loadForSave()
+	if err != nil {
+		return err
+	}
+
+	// Update only the device ID
+	if device != nil {
+		device.DeviceID = c.DeviceID
+	} else {
+		savedConfig.DeviceID = c.DeviceID
+	}
 
 	// Save back
 	return savedConfig.Save()
 }
 
+// ResetIdentity removes the saved device ID, API key and friendly ID from the config file,
+// including those of every configured device
+// With a profile selected, only that profile's identity is reset
+// The next run detects or generates a device ID and registers again; IDs read
+// from the network interface or derived from the machine ID or identity seed
+// come out the same, so only random IDs actually change
+func ResetIdentity(profile string) error {
+	savedConfig, err := LoadProfile(profile)
+	if err != nil {
+		return err
+	}
+
+	savedConfig.DeviceID = ""
+	savedConfig.APIKey = ""
+	savedConfig.FriendlyID = ""
+	for i := range savedConfig.Devices {
+		savedConfig.Devices[i].DeviceID = ""
+		savedConfig.Devices[i].APIKey = ""
+		savedConfig.Devices[i].FriendlyID = ""
+	}
+
+	return savedConfig.Save()
+}
+
 // getConfigDir returns the configuration directory path
 // Uses XDG Base Directory specification on Unix-like systems
 func getConfigDir() (string, error) {
@@ -180,24 +538,6 @@ func getConfigDir() (string, error) {
 	return filepath.Join(configHome, "trmnl"), nil
 }
 
-// Validate checks if the configuration is valid
-func (c *Config) Validate() error {
-	// Must have either API key or Device ID
-	if c.APIKey == "" && c.DeviceID == "" {
-		return fmt.Errorf("either API key or Device ID must be provided")
-	}
-
-	if c.BaseURL == "" {
-		return fmt.Errorf("base URL cannot be empty")
-	}
-
-	if c.WindowWidth <= 0 || c.WindowHeight <= 0 {
-		return fmt.Errorf("window dimensions must be positive")
-	}
-
-	return nil
-}
-
 // GetAuthHeader returns the appropriate authentication header name and value
 func (c *Config) GetAuthHeader() (string, string) {
 	if c.APIKey != "" {
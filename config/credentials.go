@@ -0,0 +1,532 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/semaja2/trmnl-go/metrics"
+)
+
+// Credential store backends (credential_store)
+const (
+	CredentialStorePlaintext = "plaintext" // API keys stay in config.json (default)
+	CredentialStoreFile      = "file"      // Encrypted credentials file next to config.json
+	CredentialStoreCommand   = "command"   // External secret helper (credential_command)
+)
+
+// CredentialPassphraseEnvVar sets the passphrase protecting the encrypted credentials file
+// When unset, a key derived from the machine ID is used
+const CredentialPassphraseEnvVar = "TRMNL_CREDENTIAL_PASSPHRASE"
+
+// CredentialsFileName is the encrypted credentials file used by the file store
+const CredentialsFileName = "credentials.enc"
+
+// DefaultCredentialCommandTimeout limits how long the secret helper may run
+const DefaultCredentialCommandTimeout = 10 * time.Second
+
+// Key derivation parameters for the encrypted credentials file
+const (
+	credentialsFileVersion = 1
+	credentialsKDF         = "pbkdf2-sha256"
+	credentialsIterations  = 600000
+)
+
+// CredentialStore keeps secrets such as API keys outside config.json
+// Secrets are addressed by name, e.g. "api_key" or "profile/staging/device/kitchen/api_key"
+type CredentialStore interface {
+	// Get returns the secret stored under name, or "" if there is none
+	Get(name string) (string, error)
+
+	// Set stores a secret under name, replacing any previous value
+	Set(name, value string) error
+
+	// Delete removes the secret stored under name (missing secrets are not an error)
+	Delete(name string) error
+}
+
+// newCredentialStore creates the backend selected by credential_store
+// Returns nil for the plaintext backend
+func newCredentialStore(kind, command string) (CredentialStore, error) {
+	switch kind {
+	case "", CredentialStorePlaintext:
+		return nil, nil
+	case CredentialStoreFile:
+		configDir, err := getConfigDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get config directory: %w", err)
+		}
+		passphrase, err := credentialPassphrase()
+		if err != nil {
+			return nil, err
+		}
+		return NewFileStore(filepath.Join(configDir, CredentialsFileName), passphrase), nil
+	case CredentialStoreCommand:
+		return NewCommandStore(command)
+	}
+	return nil, fmt.Errorf("unknown credential_store %q (expected plaintext, file or command)", kind)
+}
+
+// credentialPassphrase returns the passphrase from the environment, falling back
+// to one derived from the machine ID
+func credentialPassphrase() (string, error) {
+	if passphrase := os.Getenv(CredentialPassphraseEnvVar); passphrase != "" {
+		return passphrase, nil
+	}
+	machineID, err := metrics.MachineID()
+	if err != nil {
+		return "", fmt.Errorf("no credential passphrase: set %s (%v)", CredentialPassphraseEnvVar, err)
+	}
+	return "trmnl-go/" + machineID, nil
+}
+
+// FileStore keeps secrets in a file encrypted with AES-256-GCM, using a key
+// derived from a passphrase with PBKDF2
+type FileStore struct {
+	path       string
+	passphrase string
+}
+
+// sealedCredentials is the on-disk format of the encrypted credentials file
+type sealedCredentials struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// derivedKeys caches derived keys, as PBKDF2 is deliberately slow and the file
+// is read on every config load
+var derivedKeys sync.Map
+
+// NewFileStore creates a store backed by the encrypted file at path
+func NewFileStore(path, passphrase string) *FileStore {
+	return &FileStore{path: path, passphrase: passphrase}
+}
+
+// Get returns the secret stored under name
+func (s *FileStore) Get(name string) (string, error) {
+	secrets, _, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	return secrets[name], nil
+}
+
+// Set stores a secret under name
+func (s *FileStore) Set(name, value string) error {
+	secrets, sealed, err := s.load()
+	if err != nil {
+		return err
+	}
+	if secrets[name] == value {
+		return nil
+	}
+	secrets[name] = value
+	return s.save(secrets, sealed)
+}
+
+// Delete removes the secret stored under name
+func (s *FileStore) Delete(name string) error {
+	secrets, sealed, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[name]; !ok {
+		return nil
+	}
+	delete(secrets, name)
+	return s.save(secrets, sealed)
+}
+
+// load decrypts the credentials file (a missing file holds no secrets)
+func (s *FileStore) load() (map[string]string, *sealedCredentials, error) {
+	secrets := make(map[string]string)
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return secrets, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+
+	var sealed sealedCredentials
+	if err := json.Unmarshal(data, &sealed); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse credentials file: %w", err)
+	}
+	if sealed.Version != credentialsFileVersion || sealed.KDF != credentialsKDF {
+		return nil, nil, fmt.Errorf("unsupported credentials file (version %d, kdf %q)", sealed.Version, sealed.KDF)
+	}
+
+	gcm, err := s.cipher(sealed.Salt, sealed.Iterations)
+	if err != nil {
+		return nil, nil, err
+	}
+	plaintext, err := gcm.Open(nil, sealed.Nonce, sealed.Ciphertext, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decrypt %s (wrong passphrase or different machine?); set %s or remove the file and re-enter the API key", s.path, CredentialPassphraseEnvVar)
+	}
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse decrypted credentials: %w", err)
+	}
+	return secrets, &sealed, nil
+}
+
+// save encrypts secrets with a fresh nonce, keeping the salt of an existing file
+func (s *FileStore) save(secrets map[string]string, previous *sealedCredentials) error {
+	sealed := sealedCredentials{
+		Version:    credentialsFileVersion,
+		KDF:        credentialsKDF,
+		Iterations: credentialsIterations,
+	}
+	if previous != nil {
+		sealed.Salt, sealed.Iterations = previous.Salt, previous.Iterations
+	} else {
+		sealed.Salt = make([]byte, 16)
+		if _, err := rand.Read(sealed.Salt); err != nil {
+			return fmt.Errorf("failed to generate salt: %w", err)
+		}
+	}
+
+	gcm, err := s.cipher(sealed.Salt, sealed.Iterations)
+	if err != nil {
+		return err
+	}
+	sealed.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(sealed.Nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials: %w", err)
+	}
+	sealed.Ciphertext = gcm.Seal(nil, sealed.Nonce, plaintext, nil)
+
+	data, err := json.MarshalIndent(sealed, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := writeFileAtomic(s.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write credentials file: %w", err)
+	}
+	return nil
+}
+
+// cipher derives the file key from the passphrase and salt
+func (s *FileStore) cipher(salt []byte, iterations int) (cipher.AEAD, error) {
+	cacheKey := fmt.Sprintf("%x/%d/%s", salt, iterations, s.passphrase)
+	key, ok := derivedKeys.Load(cacheKey)
+	if !ok {
+		derived, err := pbkdf2.Key(sha256.New, s.passphrase, salt, iterations, 32)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive credentials key: %w", err)
+		}
+		key, _ = derivedKeys.LoadOrStore(cacheKey, derived)
+	}
+
+	block, err := aes.NewCipher(key.([]byte))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// CommandStore delegates to an external secret helper, in the style of git
+// credential helpers: the command is run with "get <name>", "store <name>" or
+// "erase <name>" appended
+// get prints the secret (nothing if missing), store reads it from stdin, and
+// erase must succeed for missing secrets
+type CommandStore struct {
+	command string
+	args    []string
+	timeout time.Duration
+}
+
+// NewCommandStore creates a store from a command line (split on whitespace)
+func NewCommandStore(commandLine string) (*CommandStore, error) {
+	fields := strings.Fields(commandLine)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("credential_store %q requires credential_command", CredentialStoreCommand)
+	}
+
+	return &CommandStore{
+		command: fields[0],
+		args:    fields[1:],
+		timeout: DefaultCredentialCommandTimeout,
+	}, nil
+}
+
+// Get runs the helper's get operation
+func (s *CommandStore) Get(name string) (string, error) {
+	output, err := s.run("get", name, "")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// Set runs the helper's store operation, passing the secret on stdin
+func (s *CommandStore) Set(name, value string) error {
+	_, err := s.run("store", name, value)
+	return err
+}
+
+// Delete runs the helper's erase operation
+func (s *CommandStore) Delete(name string) error {
+	_, err := s.run("erase", name, "")
+	return err
+}
+
+func (s *CommandStore) run(operation, name, input string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	args := append(append([]string{}, s.args...), operation, name)
+	cmd := exec.CommandContext(ctx, s.command, args...)
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("credential command %s %s failed: %w (%s)", operation, name, err, strings.TrimSpace(stderr.String()))
+	}
+	return string(output), nil
+}
+
+// storeForDocument opens the credential store selected by a raw config document
+// Returns nil when API keys are kept in plaintext
+func storeForDocument(doc map[string]json.RawMessage) (CredentialStore, error) {
+	var kind, command string
+	if raw, ok := doc["credential_store"]; ok {
+		if err := json.Unmarshal(raw, &kind); err != nil {
+			return nil, fmt.Errorf("invalid credential_store: %w", err)
+		}
+	}
+	if raw, ok := doc["credential_command"]; ok {
+		if err := json.Unmarshal(raw, &command); err != nil {
+			return nil, fmt.Errorf("invalid credential_command: %w", err)
+		}
+	}
+	return newCredentialStore(kind, command)
+}
+
+// sealedAPIKey is written to config.json in place of an API key kept in the
+// credential store, so only keys the store actually holds are read or erased
+const sealedAPIKey = "(credential store)"
+
+// unsealCredentials fills the sealed API keys of config file contents from the
+// credential store
+// Plaintext keys left in the file (e.g. before the store was enabled) are used as
+// they are until MoveCredentials moves them into the store
+func unsealCredentials(data []byte) ([]byte, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	store, err := storeForDocument(doc)
+	if err != nil || store == nil {
+		return data, err
+	}
+
+	err = walkCredentials(doc, "", func(name string, value *string) error {
+		if *value != sealedAPIKey {
+			return nil
+		}
+		secret, err := store.Get(name)
+		if err != nil {
+			return fmt.Errorf("failed to read %s from the credential store: %w", name, err)
+		}
+		*value = secret
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+// sealCredentials moves the API keys of config file contents into the credential
+// store before they are written, leaving sealedAPIKey in their place
+// previous is the file being replaced: keys sealed there that are now removed
+// are erased from the store, and slots that never held a key are left alone
+func sealCredentials(data, previous []byte) ([]byte, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	store, err := storeForDocument(doc)
+	if err != nil || store == nil {
+		return data, err
+	}
+
+	sealed := sealedNames(previous)
+	err = walkCredentials(doc, "", func(name string, value *string) error {
+		switch *value {
+		case "":
+			if sealed[name] {
+				return store.Delete(name)
+			}
+			return nil
+		case sealedAPIKey:
+			return nil
+		}
+		if err := store.Set(name, *value); err != nil {
+			return err
+		}
+		*value = sealedAPIKey
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update the credential store: %w", err)
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// sealedNames returns the names of the API keys config file contents keep in
+// the credential store
+func sealedNames(data []byte) map[string]bool {
+	names := make(map[string]bool)
+	var doc map[string]json.RawMessage
+	if json.Unmarshal(data, &doc) != nil {
+		return names
+	}
+	walkCredentials(doc, "", func(name string, value *string) error {
+		if *value == sealedAPIKey {
+			names[name] = true
+		}
+		return nil
+	})
+	return names
+}
+
+// hasPlaintextCredentials reports whether a raw config document selects a
+// credential store but still holds API keys in plaintext
+func hasPlaintextCredentials(doc map[string]json.RawMessage) bool {
+	if store, err := storeForDocument(doc); err != nil || store == nil {
+		return false
+	}
+	found := false
+	walkCredentials(doc, "", func(_ string, value *string) error {
+		found = found || (*value != "" && *value != sealedAPIKey)
+		return nil
+	})
+	return found
+}
+
+// MoveCredentials moves plaintext API keys left in the config file (e.g. from
+// before credential_store was set) into the credential store
+// It returns whether any keys were moved; files without such keys are not touched
+func MoveCredentials() (bool, error) {
+	configPath, err := Path()
+	if err != nil {
+		return false, err
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return false, nil // No file, no keys
+	}
+	var doc map[string]json.RawMessage
+	if json.Unmarshal(data, &doc) != nil || !hasPlaintextCredentials(doc) {
+		return false, nil
+	}
+
+	// Rewriting the file seals every key; the lock keeps concurrent updates intact
+	_, err = updateDocument("", func(_, _ map[string]json.RawMessage) error {
+		return nil
+	})
+	return err == nil, err
+}
+
+// walkCredentials calls fn with the name and value of every API key in a raw
+// config document (the base, each device and each profile), storing back values
+// that fn changes ("" removes the key)
+func walkCredentials(doc map[string]json.RawMessage, prefix string, fn func(name string, value *string) error) error {
+	if err := visitAPIKey(doc, prefix+"api_key", fn); err != nil {
+		return err
+	}
+
+	if raw, ok := doc["devices"]; ok {
+		var devices []map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &devices); err != nil {
+			return fmt.Errorf("failed to parse devices: %w", err)
+		}
+		for _, device := range devices {
+			var name string
+			if device == nil || json.Unmarshal(device["name"], &name) != nil || name == "" {
+				continue // Reported by validation
+			}
+			if err := visitAPIKey(device, prefix+"device/"+name+"/api_key", fn); err != nil {
+				return err
+			}
+		}
+		var err error
+		if doc["devices"], err = json.Marshal(devices); err != nil {
+			return fmt.Errorf("failed to marshal devices: %w", err)
+		}
+	}
+
+	if raw, ok := doc["profiles"]; ok && prefix == "" {
+		var profiles map[string]map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &profiles); err != nil {
+			return fmt.Errorf("failed to parse profiles: %w", err)
+		}
+		for name, overlay := range profiles {
+			if overlay == nil {
+				continue
+			}
+			if err := walkCredentials(overlay, "profile/"+name+"/", fn); err != nil {
+				return err
+			}
+		}
+		var err error
+		if doc["profiles"], err = json.Marshal(profiles); err != nil {
+			return fmt.Errorf("failed to marshal profiles: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// visitAPIKey calls fn for the api_key of a single JSON object
+func visitAPIKey(obj map[string]json.RawMessage, name string, fn func(name string, value *string) error) error {
+	var value string
+	if raw, ok := obj["api_key"]; ok {
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+
+	previous := value
+	if err := fn(name, &value); err != nil {
+		return err
+	}
+	if value == previous {
+		return nil
+	}
+
+	if value == "" {
+		delete(obj, "api_key")
+	} else {
+		obj["api_key"], _ = json.Marshal(value)
+	}
+	return nil
+}
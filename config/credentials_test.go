@@ -0,0 +1,139 @@
+//go:build unix
+
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// credentialHelper is a secret helper keeping secrets in files and logging each operation
+const credentialHelper = `#!/bin/sh
+echo "$1 $2" >> "$HELPER_DIR/log"
+file="$HELPER_DIR/$(echo "$2" | tr / _)"
+case "$1" in
+get) cat "$file" 2>/dev/null ;;
+store) cat > "$file" ;;
+erase) rm -f "$file" ;;
+esac
+`
+
+// newCredentialHelper installs credentialHelper in a temporary directory and
+// returns the config document prefix selecting it, and a function returning
+// (and clearing) the operations run so far
+func newCredentialHelper(t *testing.T) (string, func() []string) {
+	t.Helper()
+	dir := t.TempDir()
+	helper := filepath.Join(dir, "helper.sh")
+	if err := os.WriteFile(helper, []byte(credentialHelper), 0700); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HELPER_DIR", dir)
+
+	prefix := `"credential_store":"command","credential_command":"` + helper + `"`
+	operations := func() []string {
+		data, _ := os.ReadFile(filepath.Join(dir, "log"))
+		os.Remove(filepath.Join(dir, "log"))
+		return strings.Fields(strings.ReplaceAll(string(data), " ", "="))
+	}
+	return prefix, operations
+}
+
+func TestSealCredentials(t *testing.T) {
+	prefix, operations := newCredentialHelper(t)
+	data := []byte(`{` + prefix + `,"api_key":"SECRET","devices":[{"name":"kitchen"}],"profiles":{"staging":{"rotation":90}}}`)
+
+	// Only the slot holding a key is stored; empty slots are left alone
+	sealed, err := sealCredentials(data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := operations(), []string{"store=api_key"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sealing ran %v, want %v", got, want)
+	}
+	if strings.Contains(string(sealed), "SECRET") || !strings.Contains(string(sealed), sealedAPIKey) {
+		t.Errorf("sealed file = %s, want the key replaced with %q", sealed, sealedAPIKey)
+	}
+
+	// Only sealed slots are read back
+	unsealed, err := unsealCredentials(sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := operations(), []string{"get=api_key"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unsealing ran %v, want %v", got, want)
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(unsealed, &doc); err != nil || string(doc["api_key"]) != `"SECRET"` {
+		t.Errorf("unsealed api_key = %s (%v), want \"SECRET\"", doc["api_key"], err)
+	}
+
+	// Saving again without changes erases nothing
+	if _, err := sealCredentials(unsealed, sealed); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := operations(), []string{"store=api_key"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("saving again ran %v, want %v", got, want)
+	}
+
+	// Removing a sealed key erases it from the store
+	delete(doc, "api_key")
+	removed, _ := json.Marshal(doc)
+	if _, err := sealCredentials(removed, sealed); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := operations(), []string{"erase=api_key"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("removing the key ran %v, want %v", got, want)
+	}
+}
+
+func TestHasPlaintextCredentials(t *testing.T) {
+	prefix, _ := newCredentialHelper(t)
+	tests := []struct {
+		data string
+		want bool
+	}{
+		{`{"api_key":"SECRET"}`, false}, // No store selected
+		{`{` + prefix + `,"api_key":"SECRET"}`, true},
+		{`{` + prefix + `,"devices":[{"name":"kitchen","api_key":"SECRET"}]}`, true},
+		{`{` + prefix + `,"profiles":{"staging":{"api_key":"SECRET"}}}`, true},
+		{`{` + prefix + `,"api_key":"` + sealedAPIKey + `"}`, false},
+		{`{` + prefix + `}`, false},
+	}
+	for _, tt := range tests {
+		var doc map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(tt.data), &doc); err != nil {
+			t.Fatal(err)
+		}
+		if got := hasPlaintextCredentials(doc); got != tt.want {
+			t.Errorf("hasPlaintextCredentials(%s) = %v, want %v", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestBackupOldSchemaStripsKeys(t *testing.T) {
+	prefix, _ := newCredentialHelper(t)
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+	data := `{` + prefix + `,"api_key":"SECRET","profiles":{"staging":{"api_key":"STAGING"}}}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := backupOldSchema(path); err != nil {
+		t.Fatal(err)
+	}
+	backup, err := os.ReadFile(path + ".bak-v0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(backup), "SECRET") || strings.Contains(string(backup), "STAGING") {
+		t.Errorf("backup keeps plaintext API keys: %s", backup)
+	}
+	if !strings.Contains(string(backup), "staging") {
+		t.Errorf("backup lost the staging profile: %s", backup)
+	}
+}
@@ -0,0 +1,309 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+)
+
+// Setting sources, from lowest to highest priority
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceProfile = "profile"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+
+	// SourceCredentialStore marks an API key read from the credential store
+	SourceCredentialStore = "credential store"
+)
+
+// Sources reports where each setting comes from when loading the given profile
+// (SourceDefault, SourceFile, SourceProfile or SourceEnv), keyed by JSON name
+// Command-line flags are applied by the caller
+func Sources(profile string) (map[string]string, error) {
+	doc, err := readDocument()
+	if err != nil {
+		return nil, err
+	}
+	base, err := readFile()
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make(map[string]string)
+	for _, key := range Keys() {
+		sources[key.Name] = SourceDefault
+		if _, ok := doc[key.Name]; ok {
+			sources[key.Name] = SourceFile
+		}
+	}
+
+	if name := base.resolveProfile(profile); name != "" {
+		overlay, ok := base.Profiles[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown profile: %s", name)
+		}
+		var keys map[string]json.RawMessage
+		if err := json.Unmarshal(overlay, &keys); err != nil {
+			return nil, fmt.Errorf("failed to parse profile %s: %w", name, err)
+		}
+		for name := range keys {
+			sources[name] = SourceProfile
+		}
+	}
+
+	if base.CredentialStore != "" && base.CredentialStore != CredentialStorePlaintext && sources["api_key"] != SourceDefault {
+		sources["api_key"] = SourceCredentialStore
+	}
+
+	for _, key := range Keys() {
+		if env := key.EnvVar(); env != "" && os.Getenv(env) != "" {
+			sources[key.Name] = SourceEnv
+		}
+	}
+
+	return sources, nil
+}
+
+// UnknownKeys lists keys in the config file that don't match any setting, including
+// keys inside profiles and device entries (e.g. "profiles.staging.colour", "devices[0].colour")
+// Unknown keys are otherwise ignored when loading, so typos go unnoticed
+func UnknownKeys() ([]string, error) {
+	doc, err := readDocument()
+	if err != nil {
+		return nil, err
+	}
+
+	var unknown []string
+	unknownKeys(doc, "", &unknown)
+
+	var profiles map[string]json.RawMessage
+	if raw, ok := doc["profiles"]; ok && json.Unmarshal(raw, &profiles) == nil {
+		for name, raw := range profiles {
+			var overlay map[string]json.RawMessage
+			if json.Unmarshal(raw, &overlay) == nil {
+				unknownKeys(overlay, "profiles."+name+".", &unknown)
+			}
+		}
+	}
+
+	sort.Strings(unknown)
+	return unknown, nil
+}
+
+// unknownKeys appends the unknown keys of a settings object (and its device entries)
+func unknownKeys(values map[string]json.RawMessage, prefix string, unknown *[]string) {
+	deviceKeys := fieldKeys(reflect.TypeOf(Device{}))
+
+	for name, raw := range values {
+		if _, ok := LookupKey(name); !ok {
+			*unknown = append(*unknown, prefix+name)
+			continue
+		}
+
+		if name != "devices" {
+			continue
+		}
+		var devices []map[string]json.RawMessage
+		if json.Unmarshal(raw, &devices) != nil {
+			continue
+		}
+		for i, device := range devices {
+			for key := range device {
+				if !deviceKeys[key] {
+					*unknown = append(*unknown, fmt.Sprintf("%sdevices[%d].%s", prefix, i, key))
+				}
+			}
+		}
+	}
+}
+
+// SetValue sets a key in the config file after validating the result
+// The key is written to the profile selected as in LoadProfile (creating the
+// profile if needed), or to the shared base when no profile is selected
+// Returns the name of the profile that was changed ("" for the base)
+func SetValue(profile, name, value string) (string, error) {
+	key, ok := LookupKey(name)
+	if !ok {
+		return "", fmt.Errorf("unknown key: %s", name)
+	}
+	if name == schemaVersionKey {
+		return "", fmt.Errorf("%s is managed automatically", name)
+	}
+	raw, err := key.Parse(value)
+	if err != nil {
+		return "", err
+	}
+
+	return updateDocument(profile, func(values, _ map[string]json.RawMessage) error {
+		values[name] = raw
+		return nil
+	})
+}
+
+// UnsetValue removes a key from the config file (or the selected profile),
+// so it falls back to the shared base or the default
+// Returns the name of the profile that was changed ("" for the base)
+func UnsetValue(profile, name string) (string, error) {
+	if _, ok := LookupKey(name); !ok {
+		return "", fmt.Errorf("unknown key: %s", name)
+	}
+	if name == schemaVersionKey {
+		return "", fmt.Errorf("%s is managed automatically", name)
+	}
+
+	return updateDocument(profile, func(values, _ map[string]json.RawMessage) error {
+		delete(values, name)
+		return nil
+	})
+}
+
+// updateDocument applies change to the base settings or the selected profile's
+// overrides, then validates and writes the file
+// change receives the values to update and the base document (the same map when
+// no profile is selected)
+// The file is locked from reading until the write completes, and working on the
+// raw document keeps defaults and environment variables out of the file
+func updateDocument(profile string, change func(values, base map[string]json.RawMessage) error) (string, error) {
+	unlock, err := lockConfig()
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	doc, err := readDocument()
+	if err != nil {
+		return "", err
+	}
+	base, err := readFile()
+	if err != nil {
+		return "", err
+	}
+
+	name := base.resolveProfile(profile)
+	if name == "" {
+		if err := change(doc, doc); err != nil {
+			return "", err
+		}
+	} else {
+		overlay := make(map[string]json.RawMessage)
+		if raw, ok := base.Profiles[name]; ok {
+			if err := json.Unmarshal(raw, &overlay); err != nil {
+				return "", fmt.Errorf("failed to parse profile %s: %w", name, err)
+			}
+		}
+		if err := change(overlay, doc); err != nil {
+			return "", err
+		}
+
+		profiles := make(map[string]json.RawMessage)
+		if raw, ok := doc["profiles"]; ok {
+			if err := json.Unmarshal(raw, &profiles); err != nil {
+				return "", fmt.Errorf("failed to parse profiles: %w", err)
+			}
+		}
+		if profiles[name], err = json.Marshal(overlay); err != nil {
+			return "", fmt.Errorf("failed to marshal profile: %w", err)
+		}
+		if doc["profiles"], err = json.Marshal(profiles); err != nil {
+			return "", fmt.Errorf("failed to marshal profiles: %w", err)
+		}
+	}
+
+	doc[schemaVersionKey], _ = json.Marshal(SchemaVersion)
+
+	if err := validateDocument(doc, name); err != nil {
+		return "", err
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal config: %w", err)
+	}
+	configPath, err := Path()
+	if err != nil {
+		return "", err
+	}
+	if err := backupOldSchema(configPath); err != nil {
+		return "", err
+	}
+	return name, writeConfigFile(data)
+}
+
+// validateDocument checks the configuration a document would load with the given profile
+func validateDocument(doc map[string]json.RawMessage, profile string) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	cfg := defaultConfig()
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	if profile != "" {
+		if err := cfg.applyProfile(profile); err != nil {
+			return err
+		}
+	}
+
+	return cfg.ValidateSettings()
+}
+
+// readDocument reads the config file as raw top-level JSON values
+// A missing file yields an empty document
+func readDocument() (map[string]json.RawMessage, error) {
+	configPath, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	doc := make(map[string]json.RawMessage)
+	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return doc, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if data, err = decodeConfigFile(data); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	return doc, nil
+}
+
+// applyEnv overrides settings with their environment variables
+// Empty variables are ignored; values are parsed as in Key.Parse
+func (c *Config) applyEnv() error {
+	for _, key := range Keys() {
+		env := key.EnvVar()
+		if env == "" {
+			continue
+		}
+		if value := os.Getenv(env); value != "" {
+			if err := c.set(key, value); err != nil {
+				return fmt.Errorf("invalid %s: %w", env, err)
+			}
+		}
+	}
+	return nil
+}
+
+// set sets a single key from its string form (see Key.Parse)
+func (c *Config) set(key Key, value string) error {
+	raw, err := key.Parse(value)
+	if err != nil {
+		return err
+	}
+
+	// Reset the field so structured values are replaced rather than merged
+	field := reflect.ValueOf(c).Elem().Field(key.index)
+	field.Set(reflect.Zero(field.Type()))
+	return json.Unmarshal(raw, field.Addr().Interface())
+}
@@ -0,0 +1,204 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Key describes a setting, addressed by its JSON name in the config file
+type Key struct {
+	Name      string       // JSON name (e.g. "dark_mode")
+	Type      reflect.Type // Go type of the Config field
+	Sensitive bool         // Whether values must be redacted when displayed
+	index     int          // Field index in Config
+}
+
+// EnvPrefix is prepended to the upper-cased key name to form its environment variable
+const EnvPrefix = "TRMNL_"
+
+// Keys holding secrets, redacted wherever they appear
+var sensitiveKeys = map[string]bool{
+	"api_key": true,
+}
+
+// Keys returns all settings in the order they are declared in Config
+func Keys() []Key {
+	t := reflect.TypeOf(Config{})
+	keys := make([]Key, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := jsonKey(field)
+		if name == "" {
+			continue
+		}
+		keys = append(keys, Key{
+			Name:      name,
+			Type:      field.Type,
+			Sensitive: sensitiveKeys[name],
+			index:     i,
+		})
+	}
+	return keys
+}
+
+// LookupKey finds a setting by its JSON name
+func LookupKey(name string) (Key, bool) {
+	for _, key := range Keys() {
+		if key.Name == name {
+			return key, true
+		}
+	}
+	return Key{}, false
+}
+
+// EnvVar returns the environment variable overriding the key (e.g. TRMNL_DARK_MODE)
+// Keys that only make sense in the config file return ""
+func (k Key) EnvVar() string {
+	if baseOnlyKeys[k.Name] {
+		return ""
+	}
+	return EnvPrefix + strings.ToUpper(k.Name)
+}
+
+// TypeName describes the accepted value format (string, bool, int or JSON)
+func (k Key) TypeName() string {
+	switch k.Type.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int:
+		return k.Type.Kind().String()
+	}
+	return "JSON"
+}
+
+// Parse converts a command-line or environment string to the key's JSON value
+// Strings are taken literally, booleans and integers are parsed, and structured
+// values (lists, objects) must be given as JSON
+func (k Key) Parse(value string) (json.RawMessage, error) {
+	switch k.Type.Kind() {
+	case reflect.String:
+		return json.Marshal(value)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false (got %q)", k.Name, value)
+		}
+		return json.Marshal(b)
+
+	case reflect.Int:
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an integer (got %q)", k.Name, value)
+		}
+		return json.Marshal(n)
+	}
+
+	raw := json.RawMessage(value)
+	target := reflect.New(k.Type).Interface()
+	if err := json.Unmarshal(raw, target); err != nil {
+		return nil, fmt.Errorf("%s must be JSON matching the config file format: %w", k.Name, err)
+	}
+	return raw, nil
+}
+
+// Value returns the current value of a key formatted for display
+// Strings, booleans and numbers are printed plainly, other values as JSON
+func (c *Config) Value(key Key) string {
+	v := reflect.ValueOf(c).Elem().Field(key.index)
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool, reflect.Int:
+		return fmt.Sprint(v.Interface())
+	}
+
+	if v.IsZero() {
+		return ""
+	}
+	data, err := json.Marshal(v.Interface())
+	if err != nil {
+		return fmt.Sprintf("<%v>", err)
+	}
+	return string(data)
+}
+
+// DisplayValue returns the value of a key with secrets redacted, including
+// secrets nested inside structured values such as devices and profiles
+func (c *Config) DisplayValue(key Key) string {
+	value := c.Value(key)
+	if value == "" {
+		return ""
+	}
+	if key.Sensitive {
+		return RedactSensitive(value)
+	}
+
+	switch key.Type.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int:
+		return value
+	}
+
+	var decoded any
+	if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+		return value
+	}
+	data, err := json.Marshal(redactJSON(decoded))
+	if err != nil {
+		return value
+	}
+	return string(data)
+}
+
+// redactJSON replaces the values of sensitive keys anywhere in a decoded JSON value
+func redactJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for name, value := range v {
+			if s, ok := value.(string); ok && sensitiveKeys[name] {
+				v[name] = RedactSensitive(s)
+			} else {
+				v[name] = redactJSON(value)
+			}
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = redactJSON(value)
+		}
+	}
+	return v
+}
+
+// zeroValueJSON returns the JSON encoding of the zero value of the key with the given name
+func zeroValueJSON(name string) json.RawMessage {
+	if key, ok := LookupKey(name); ok {
+		if data, err := json.Marshal(reflect.Zero(key.Type).Interface()); err == nil {
+			return data
+		}
+	}
+	return json.RawMessage("null")
+}
+
+// fieldKeys returns the JSON keys of a struct type
+func fieldKeys(t reflect.Type) map[string]bool {
+	keys := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		if name := jsonKey(t.Field(i)); name != "" {
+			keys[name] = true
+		}
+	}
+	return keys
+}
+
+// jsonKey returns the JSON object key of a struct field ("" if not serialized)
+func jsonKey(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,256 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+)
+
+// lockFileName is the advisory lock guarding config file updates
+// A separate file is locked because config.json itself is replaced on every write
+const lockFileName = "config.json.lock"
+
+// configMu serializes config file updates between goroutines; the file lock
+// does the same between processes
+var configMu sync.Mutex
+
+// lockConfig takes an exclusive lock on the config file, waiting for other
+// instances to finish their updates; call the returned function to release it
+func lockConfig() (func(), error) {
+	configMu.Lock()
+
+	configDir, err := getConfigDir()
+	if err != nil {
+		configMu.Unlock()
+		return nil, fmt.Errorf("failed to get config directory: %w", err)
+	}
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		configMu.Unlock()
+		return nil, fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	f, err := os.OpenFile(filepath.Join(configDir, lockFileName), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		configMu.Unlock()
+		return nil, fmt.Errorf("failed to open config lock: %w", err)
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		configMu.Unlock()
+		return nil, fmt.Errorf("failed to lock config file: %w", err)
+	}
+
+	return func() {
+		unlockFile(f)
+		f.Close()
+		configMu.Unlock()
+	}, nil
+}
+
+// writeFileAtomic replaces path with data so readers see either the old or the new
+// contents, never a partial write: data is written to a temporary file in the same
+// directory, flushed to disk and renamed over path
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // Fails harmlessly once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+
+	// Persist the rename itself
+	return syncDir(dir)
+}
+
+// mergeValue applies the change from old to next onto current, the value now in
+// the file (nil means absent)
+// Objects are merged key by key and lists of named entries (devices) entry by
+// entry, so concurrent changes to other settings are kept; any other value is
+// replaced by next
+func mergeValue(old, next, current json.RawMessage) (json.RawMessage, error) {
+	if jsonEqual(old, next) {
+		return current, nil
+	}
+	if next == nil {
+		return nil, nil
+	}
+
+	if oldObj, ok := asObject(old); ok {
+		if nextObj, ok := asObject(next); ok {
+			if currentObj, ok := asObject(current); ok {
+				return mergeObjects(oldObj, nextObj, currentObj)
+			}
+		}
+	}
+
+	if oldList, ok := asNamedList(old); ok {
+		if nextList, ok := asNamedList(next); ok {
+			if currentList, ok := asNamedList(current); ok {
+				return mergeNamedLists(oldList, nextList, currentList)
+			}
+		}
+	}
+
+	return next, nil
+}
+
+func mergeObjects(old, next, current map[string]json.RawMessage) (json.RawMessage, error) {
+	merged := make(map[string]json.RawMessage, len(current))
+	for key, value := range current {
+		merged[key] = value
+	}
+
+	for _, key := range unionKeys(old, next) {
+		value, err := mergeValue(old[key], next[key], current[key])
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			delete(merged, key)
+		} else {
+			merged[key] = value
+		}
+	}
+
+	return json.Marshal(merged)
+}
+
+func mergeNamedLists(old, next, current []map[string]json.RawMessage) (json.RawMessage, error) {
+	merged := append([]map[string]json.RawMessage{}, current...)
+	find := func(list []map[string]json.RawMessage, name string) (json.RawMessage, int) {
+		for i, entry := range list {
+			if entryName(entry) == name {
+				data, _ := json.Marshal(entry)
+				return data, i
+			}
+		}
+		return nil, -1
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, entry := range append(append([]map[string]json.RawMessage{}, old...), next...) {
+		if name := entryName(entry); !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	for _, name := range names {
+		oldEntry, _ := find(old, name)
+		nextEntry, _ := find(next, name)
+		currentEntry, index := find(merged, name)
+
+		value, err := mergeValue(oldEntry, nextEntry, currentEntry)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case value == nil && index >= 0:
+			merged = append(merged[:index], merged[index+1:]...)
+		case value != nil:
+			var entry map[string]json.RawMessage
+			if err := json.Unmarshal(value, &entry); err != nil {
+				return nil, fmt.Errorf("failed to merge %s: %w", name, err)
+			}
+			if index >= 0 {
+				merged[index] = entry
+			} else {
+				merged = append(merged, entry)
+			}
+		}
+	}
+
+	return json.Marshal(merged)
+}
+
+// asObject parses a JSON object (an absent value counts as an empty object)
+func asObject(value json.RawMessage) (map[string]json.RawMessage, bool) {
+	obj := make(map[string]json.RawMessage)
+	if value == nil {
+		return obj, true
+	}
+	if err := json.Unmarshal(value, &obj); err != nil || obj == nil {
+		return nil, false
+	}
+	return obj, true
+}
+
+// asNamedList parses a JSON list whose entries are objects with a unique "name"
+// (an absent value counts as an empty list)
+func asNamedList(value json.RawMessage) ([]map[string]json.RawMessage, bool) {
+	var list []map[string]json.RawMessage
+	if value == nil {
+		return list, true
+	}
+	if err := json.Unmarshal(value, &list); err != nil {
+		return nil, false
+	}
+	seen := make(map[string]bool)
+	for _, entry := range list {
+		name := entryName(entry)
+		if name == "" || seen[name] {
+			return nil, false
+		}
+		seen[name] = true
+	}
+	return list, true
+}
+
+func entryName(entry map[string]json.RawMessage) string {
+	var name string
+	if entry != nil {
+		json.Unmarshal(entry["name"], &name)
+	}
+	return name
+}
+
+// jsonEqual compares two JSON values, ignoring formatting and key order
+func jsonEqual(a, b json.RawMessage) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	var va, vb any
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return string(a) == string(b)
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// unionKeys returns the keys present in either object
+func unionKeys(a, b map[string]json.RawMessage) []string {
+	var keys []string
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// ProfileEnvVar selects a profile when -profile isn't given
+const ProfileEnvVar = "TRMNL_PROFILE"
+
+// Keys that belong to the shared base and are never stored in a profile
+var baseOnlyKeys = map[string]bool{
+	"profiles":        true,
+	"default_profile": true,
+	schemaVersionKey:  true,
+
+	// The credential store must be known before any profile can be read
+	"credential_store":   true,
+	"credential_command": true,
+}
+
+// Profile returns the name of the active profile ("" when using the base config only)
+func (c *Config) Profile() string {
+	return c.profile
+}
+
+// ProfileNames returns the names of all profiles in sorted order
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolveProfile picks the profile to load: the requested name,
+// then the TRMNL_PROFILE environment variable, then the default profile
+func (c *Config) resolveProfile(name string) string {
+	if name != "" {
+		return name
+	}
+	if env := os.Getenv(ProfileEnvVar); env != "" {
+		return env
+	}
+	return c.DefaultProfile
+}
+
+// applyProfile overlays the named profile on the base configuration
+// Keys set in the profile replace the base values; lists are replaced, not merged
+func (c *Config) applyProfile(name string) error {
+	overlay, ok := c.Profiles[name]
+	if !ok {
+		return fmt.Errorf("unknown profile: %s", name)
+	}
+
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(overlay, &keys); err != nil {
+		return fmt.Errorf("failed to parse profile %s: %w", name, err)
+	}
+	for key := range keys {
+		if baseOnlyKeys[key] {
+			return fmt.Errorf("profile %s cannot set %s", name, key)
+		}
+	}
+	if _, ok := keys["devices"]; ok {
+		c.Devices = nil
+	}
+	if _, ok := keys["battery_curve"]; ok {
+		c.BatteryCurve = nil
+	}
+
+	if err := json.Unmarshal(overlay, c); err != nil {
+		return fmt.Errorf("failed to parse profile %s: %w", name, err)
+	}
+	c.profile = name
+
+	return nil
+}
+
+// SameSettings reports whether two configs hold the same settings
+// Only the values saved in config files are compared, not internal state such
+// as the values last saved, nor the schema version stamped on the first save
+func (c *Config) SameSettings(other *Config) bool {
+	a, errA := configValues(c)
+	b, errB := configValues(other)
+	if errA != nil || errB != nil {
+		return false
+	}
+	for _, key := range unionKeys(a, b) {
+		if key != schemaVersionKey && !jsonEqual(a[key], b[key]) {
+			return false
+		}
+	}
+	return true
+}
+
+// configValues marshals a config into its top-level JSON values
+func configValues(c *Config) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return values, nil
+}
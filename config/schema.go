@@ -0,0 +1,146 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// SchemaVersion is the config file format written by this version
+// Bump it together with a new entry in migrations whenever a change to Config
+// needs existing files to be rewritten
+const SchemaVersion = 1
+
+// schemaVersionKey is the JSON name of Config.SchemaVersion
+const schemaVersionKey = "schema_version"
+
+// migration upgrades a raw config document by one schema version
+type migration struct {
+	description string
+	apply       func(doc map[string]json.RawMessage) error
+}
+
+// migrations[i] upgrades a document from schema version i to i+1
+// Files written before versioning was introduced have no schema_version and are version 0
+var migrations = []migration{
+	{"record the schema version", stampSchemaVersion},
+}
+
+// NewerSchemaError is returned when saving over a config file written by a newer version
+type NewerSchemaError struct {
+	Version int // Schema version found in the file
+}
+
+func (e *NewerSchemaError) Error() string {
+	return fmt.Sprintf("config file uses schema version %d, but this version of trmnl-go only supports %d; refusing to overwrite it (upgrade trmnl-go)", e.Version, SchemaVersion)
+}
+
+// documentVersion returns the schema version of a raw config document (0 if unversioned)
+func documentVersion(doc map[string]json.RawMessage) (int, error) {
+	raw, ok := doc[schemaVersionKey]
+	if !ok {
+		return 0, nil
+	}
+	var version int
+	if err := json.Unmarshal(raw, &version); err != nil || version < 0 {
+		return 0, fmt.Errorf("invalid schema_version: %s", raw)
+	}
+	return version, nil
+}
+
+// migrateData upgrades config file contents written by an older version in memory
+// Current and newer files are returned unchanged. Reading never writes: the file is
+// rewritten in the current format the next time settings are saved (see backupOldSchema)
+func migrateData(data []byte) ([]byte, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	version, err := documentVersion(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if version >= SchemaVersion {
+		return data, nil
+	}
+
+	for v := version; v < SchemaVersion; v++ {
+		if err := migrations[v].apply(doc); err != nil {
+			return nil, fmt.Errorf("failed to migrate config from schema version %d (%s): %w", v, migrations[v].description, err)
+		}
+	}
+	doc[schemaVersionKey], _ = json.Marshal(SchemaVersion)
+
+	migrated, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal migrated config: %w", err)
+	}
+	return migrated, nil
+}
+
+// backupOldSchema keeps a config file written by an older version next to it as
+// config.json.bak-v<version>, before it is replaced in the current format
+// The caller must hold the config lock
+func backupOldSchema(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil // Nothing to keep
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil // Reported when the file is read
+	}
+	version, err := documentVersion(doc)
+	if err != nil || version >= SchemaVersion {
+		return nil
+	}
+
+	// Keep the oldest original if an earlier upgrade attempt already made a backup
+	backupPath := fmt.Sprintf("%s.bak-v%d", path, version)
+	if _, err := os.Stat(backupPath); !os.IsNotExist(err) {
+		return nil
+	}
+
+	// With a credential store, API keys are left out: the rewritten file moves them
+	// into the store, and the backup must not keep a plaintext copy
+	if store, err := storeForDocument(doc); err == nil && store != nil {
+		err := walkCredentials(doc, "", func(_ string, value *string) error {
+			*value = ""
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to back up config file: %w", err)
+		}
+		if data, err = json.MarshalIndent(doc, "", "  "); err != nil {
+			return fmt.Errorf("failed to back up config file: %w", err)
+		}
+	}
+
+	if err := os.WriteFile(backupPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to back up config file: %w", err)
+	}
+	return nil
+}
+
+// checkWritable refuses to replace a config file written by a newer version,
+// whose settings this version would silently drop
+func checkWritable(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil
+	}
+	if version, err := documentVersion(doc); err == nil && version > SchemaVersion {
+		return &NewerSchemaError{Version: version}
+	}
+	return nil
+}
+
+// stampSchemaVersion (0 -> 1) leaves settings as they are: files written before
+// versioning only gain the schema_version field, set by migrateData
+func stampSchemaVersion(doc map[string]json.RawMessage) error {
+	return nil
+}
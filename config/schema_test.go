@@ -0,0 +1,126 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// decodeDocument parses raw config contents for comparisons
+func decodeDocument(t *testing.T, data []byte) map[string]any {
+	t.Helper()
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("invalid JSON %s: %v", data, err)
+	}
+	return doc
+}
+
+func TestMigrateData(t *testing.T) {
+	t.Run("unversioned file keeps its settings", func(t *testing.T) {
+		data := []byte(`{"device_id":"aa-bb-cc-dd-ee-ff","rotation":90,"devices":[{"name":"kitchen","device_id":"11:22:33:44:55:66"}]}`)
+		migrated, err := migrateData(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		doc := decodeDocument(t, migrated)
+		if doc[schemaVersionKey] != float64(SchemaVersion) {
+			t.Errorf("schema_version = %v, want %d", doc[schemaVersionKey], SchemaVersion)
+		}
+		delete(doc, schemaVersionKey)
+		if want := decodeDocument(t, data); !reflect.DeepEqual(doc, want) {
+			t.Errorf("settings changed by migration: %v, want %v", doc, want)
+		}
+	})
+
+	t.Run("current and newer files are unchanged", func(t *testing.T) {
+		for _, data := range []string{`{"schema_version":1,"rotation":90}`, `{"schema_version":99,"future":true}`} {
+			migrated, err := migrateData([]byte(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(migrated) != data {
+				t.Errorf("migrateData(%s) = %s, want it unchanged", data, migrated)
+			}
+		}
+	})
+
+	t.Run("invalid files", func(t *testing.T) {
+		for _, data := range []string{`{"schema_version":-1}`, `{"schema_version":"1"}`, `not json`} {
+			if _, err := migrateData([]byte(data)); err == nil {
+				t.Errorf("migrateData(%s) succeeded, want an error", data)
+			}
+		}
+	})
+}
+
+func TestBackupOldSchema(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ConfigFileName)
+	backup := path + ".bak-v0"
+
+	original := []byte(`{"rotation":90}`)
+	if err := os.WriteFile(path, original, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := backupOldSchema(path); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(backup); err != nil || string(data) != string(original) {
+		t.Fatalf("backup = %s (%v), want %s", data, err, original)
+	}
+
+	// The oldest original is kept
+	if err := os.WriteFile(path, []byte(`{"rotation":180}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := backupOldSchema(path); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(backup); string(data) != string(original) {
+		t.Errorf("backup replaced with %s, want %s", data, original)
+	}
+
+	// Current files and missing files need no backup
+	current := filepath.Join(dir, "current.json")
+	if err := os.WriteFile(current, []byte(`{"schema_version":1}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{current, filepath.Join(dir, "missing.json")} {
+		if err := backupOldSchema(p); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := os.Stat(p + ".bak-v0"); !os.IsNotExist(err) {
+			t.Errorf("%s was backed up", filepath.Base(p))
+		}
+	}
+}
+
+func TestCheckWritable(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	var newer *NewerSchemaError
+	if err := checkWritable(write("newer.json", `{"schema_version":99}`)); !errors.As(err, &newer) || newer.Version != 99 {
+		t.Errorf("newer file: err = %v, want NewerSchemaError for version 99", err)
+	}
+	for _, path := range []string{
+		write("current.json", `{"schema_version":1}`),
+		write("old.json", `{"rotation":90}`),
+		write("invalid.json", `not json`),
+		filepath.Join(dir, "missing.json"),
+	} {
+		if err := checkWritable(path); err != nil {
+			t.Errorf("%s: err = %v, want nil", filepath.Base(path), err)
+		}
+	}
+}
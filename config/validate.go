@@ -0,0 +1,224 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/semaja2/trmnl-go/metrics"
+	"github.com/semaja2/trmnl-go/models"
+)
+
+// MaxWindowDimension is the largest accepted window width or height
+const MaxWindowDimension = 8192
+
+// ValidationError lists every problem found in a configuration
+type ValidationError struct {
+	Problems []string
+}
+
+// Error returns the problems, one per line when there are several
+func (e *ValidationError) Error() string {
+	if len(e.Problems) == 1 {
+		return e.Problems[0]
+	}
+	return fmt.Sprintf("%d problems:\n  - %s", len(e.Problems), strings.Join(e.Problems, "\n  - "))
+}
+
+// problems collects validation messages
+type problems []string
+
+func (p *problems) addf(format string, args ...any) {
+	*p = append(*p, fmt.Sprintf(format, args...))
+}
+
+// err returns a *ValidationError, or nil if there are no problems
+func (p problems) err() error {
+	if len(p) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: p}
+}
+
+// Validate checks if the configuration is valid, reporting all problems at once
+func (c *Config) Validate() error {
+	var p problems
+
+	// Must have either API key or Device ID
+	if c.APIKey == "" && c.DeviceID == "" {
+		p.addf("either API key or Device ID must be provided")
+	}
+
+	c.checkSettings(&p)
+	return p.err()
+}
+
+// ValidateSettings checks the settings without requiring credentials,
+// which may still be detected or registered at startup
+func (c *Config) ValidateSettings() error {
+	var p problems
+	c.checkSettings(&p)
+	return p.err()
+}
+
+// checkSettings adds a problem for every invalid setting
+func (c *Config) checkSettings(p *problems) {
+	if err := checkBaseURL(c.BaseURL); err != nil {
+		p.addf("%v", err)
+	}
+	checkDeviceID(p, "", c.DeviceID)
+	checkModel(p, "", c.Model)
+	checkWindowSize(p, "", c.WindowWidth, c.WindowHeight)
+	checkRotation(p, "", c.Rotation)
+
+	if c.LogFlushInterval <= 0 {
+		p.addf("log_flush_interval must be positive (got %d)", c.LogFlushInterval)
+	}
+
+	if c.HistorySize < 0 {
+		p.addf("history_size must not be negative (got %d)", c.HistorySize)
+	}
+
+	if c.MetricsAddr != "" {
+		if _, _, err := net.SplitHostPort(c.MetricsAddr); err != nil {
+			p.addf("metrics_addr %q must be host:port or :port", c.MetricsAddr)
+		}
+	}
+
+	switch c.MetricsProvider {
+	case "", metrics.ProviderHost, metrics.ProviderSimulated:
+	case metrics.ProviderStatic:
+		if c.StaticMetrics == nil {
+			p.addf("metrics_provider %q requires static_metrics", c.MetricsProvider)
+		}
+	case metrics.ProviderCommand:
+		if strings.TrimSpace(c.MetricsCommand) == "" {
+			p.addf("metrics_provider %q requires metrics_command", c.MetricsProvider)
+		}
+	default:
+		p.addf("unknown metrics_provider %q (expected host, static, simulated or command)", c.MetricsProvider)
+	}
+
+	switch c.CredentialStore {
+	case "", CredentialStorePlaintext, CredentialStoreFile:
+	case CredentialStoreCommand:
+		if strings.TrimSpace(c.CredentialCommand) == "" {
+			p.addf("credential_store %q requires credential_command", c.CredentialStore)
+		}
+	default:
+		p.addf("unknown credential_store %q (expected plaintext, file or command)", c.CredentialStore)
+	}
+
+	switch c.ScreenshotFormat {
+	case "", "png", "bmp", "raw":
+	default:
+		p.addf("unknown screenshot_format %q (expected png, bmp or raw)", c.ScreenshotFormat)
+	}
+
+	if len(c.BatteryCurve) > 0 {
+		if err := c.BatteryCurve.Validate(); err != nil {
+			p.addf("invalid battery_curve: %v", err)
+		}
+	}
+
+	if c.Simulation != nil {
+		if err := c.Simulation.Validate(); err != nil {
+			p.addf("invalid simulation: %v", err)
+		}
+	}
+
+	if c.DefaultProfile != "" {
+		if _, ok := c.Profiles[c.DefaultProfile]; !ok {
+			p.addf("default_profile %q is not defined in profiles", c.DefaultProfile)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i, d := range c.Devices {
+		if d.Name == "" {
+			p.addf("devices[%d] has no name", i)
+			continue
+		}
+		if seen[d.Name] {
+			p.addf("duplicate device name %q", d.Name)
+			continue
+		}
+		seen[d.Name] = true
+
+		// Inherited values were checked above; only check the device's own
+		prefix := fmt.Sprintf("device %s: ", d.Name)
+		if d.BaseURL != "" {
+			if err := checkBaseURL(d.BaseURL); err != nil {
+				p.addf("%s%v", prefix, err)
+			}
+		}
+		checkDeviceID(p, prefix, d.DeviceID)
+		checkModel(p, prefix, d.Model)
+		if d.WindowWidth < 0 || d.WindowHeight < 0 || d.WindowWidth > MaxWindowDimension || d.WindowHeight > MaxWindowDimension {
+			p.addf("%swindow size %dx%d must be between 1 and %d pixels", prefix, d.WindowWidth, d.WindowHeight, MaxWindowDimension)
+		}
+		if d.Rotation != nil {
+			checkRotation(p, prefix, *d.Rotation)
+		}
+	}
+}
+
+// checkDeviceID requires device IDs to be MAC addresses
+// prefix identifies the device in messages
+func checkDeviceID(p *problems, prefix, deviceID string) {
+	if deviceID != "" && !IsMACAddress(deviceID) {
+		p.addf("%sdevice_id %q is not a MAC address (expected AA:BB:CC:DD:EE:FF)", prefix, deviceID)
+	}
+}
+
+// checkModel requires a predefined model
+func checkModel(p *problems, prefix, model string) {
+	if model == "" {
+		return
+	}
+	if _, err := models.GetModel(model); err != nil {
+		p.addf("%s%v (use -list-models to see available models)", prefix, err)
+	}
+}
+
+// checkWindowSize requires positive dimensions up to MaxWindowDimension
+func checkWindowSize(p *problems, prefix string, width, height int) {
+	if width <= 0 || height <= 0 || width > MaxWindowDimension || height > MaxWindowDimension {
+		p.addf("%swindow size %dx%d must be between 1 and %d pixels", prefix, width, height, MaxWindowDimension)
+	}
+}
+
+// checkRotation requires a right-angle rotation
+func checkRotation(p *problems, prefix string, rotation int) {
+	switch rotation {
+	case 0, 90, 180, 270:
+	default:
+		p.addf("%srotation must be 0, 90, 180 or 270 (got %d)", prefix, rotation)
+	}
+}
+
+// checkBaseURL requires an absolute http or https URL
+func checkBaseURL(baseURL string) error {
+	if baseURL == "" {
+		return fmt.Errorf("base URL cannot be empty")
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("base_url %q is not a valid URL: %v", baseURL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("base_url %q must be an http:// or https:// URL", baseURL)
+	}
+	return nil
+}
+
+// IsMACAddress reports whether s is a MAC address such as AA:BB:CC:DD:EE:FF
+// (or AA-BB-CC-DD-EE-FF)
+func IsMACAddress(s string) bool {
+	if len(s) != 17 {
+		return false
+	}
+	_, err := net.ParseMAC(s)
+	return err == nil
+}
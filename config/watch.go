@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// WatchDebounce is how long the watcher waits for writes to settle before reporting a change
+const WatchDebounce = 250 * time.Millisecond
+
+// Watcher reports changes to the config file
+type Watcher struct {
+	watcher *fsnotify.Watcher
+	changes chan struct{}
+}
+
+// NewWatcher starts watching the config file for changes
+// The config directory is watched, so editors that replace the file are noticed too
+func NewWatcher() (*Watcher, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config directory: %w", err)
+	}
+
+	// The directory must exist to be watched
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create watcher: %w", err)
+	}
+	if err := fsw.Add(configDir); err != nil {
+		fsw.Close()
+		return nil, fmt.Errorf("failed to watch %s: %w", configDir, err)
+	}
+
+	w := &Watcher{
+		watcher: fsw,
+		changes: make(chan struct{}, 1), // Buffered so a pending change isn't lost
+	}
+	go w.run()
+
+	return w, nil
+}
+
+// Changes returns a channel that receives a value once the config file has changed
+func (w *Watcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+// Close stops watching
+func (w *Watcher) Close() error {
+	return w.watcher.Close()
+}
+
+// run collapses bursts of file events into a single change notification
+func (w *Watcher) run() {
+	var debounce *time.Timer
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				if debounce != nil {
+					debounce.Stop()
+				}
+				return
+			}
+			if filepath.Base(event.Name) != ConfigFileName {
+				continue
+			}
+			if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+				continue
+			}
+			if debounce == nil {
+				debounce = time.AfterFunc(WatchDebounce, w.notify)
+			} else {
+				debounce.Reset(WatchDebounce)
+			}
+
+		case _, ok := <-w.watcher.Errors:
+			// Errors (e.g. event queue overflow) are not fatal; keep watching
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// notify sends a change notification without blocking
+func (w *Watcher) notify() {
+	select {
+	case w.changes <- struct{}{}:
+	default:
+		// Change already pending
+	}
+}
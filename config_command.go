@@ -0,0 +1,267 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/semaja2/trmnl-go/config"
+)
+
+const configUsage = `Usage: trmnl-go config <command> [flags] [key] [value]
+
+Commands:
+  show               Show the effective configuration and where each value comes from
+  get <key>          Print the effective value of a key
+  set <key> <value>  Set a key in the config file (or the selected profile)
+  unset <key>        Remove a key from the config file (or the selected profile)
+  path               Print the config file location
+
+Flags are the same as for trmnl-go (e.g. -profile staging) and must come before
+the key. Secrets are redacted unless -reveal is given.
+Structured values (devices, simulation, ...) are set as JSON.
+`
+
+// flagKeys maps command-line flags to the config keys they override
+var flagKeys = map[string]string{
+	"api-key":            "api_key",
+	"device-id":          "device_id",
+	"mac-address":        "device_id",
+	"identity-seed":      "identity_seed",
+	"base-url":           "base_url",
+	"model":              "model",
+	"width":              "window_width",
+	"height":             "window_height",
+	"dark":               "dark_mode",
+	"no-dark":            "dark_mode",
+	"epaper":             "epaper_mode",
+	"no-epaper":          "epaper_mode",
+	"always-on-top":      "always_on_top",
+	"fullscreen":         "fullscreen",
+	"rotation":           "rotation",
+	"mirror":             "mirror_mode",
+	"verbose":            "verbose",
+	"log-flush-interval": "log_flush_interval",
+	"metrics-addr":       "metrics_addr",
+	"metrics-provider":   "metrics_provider",
+	"metrics-command":    "metrics_command",
+	"simulate-battery":   "simulation",
+	"simulate-rssi":      "simulation",
+}
+
+// runConfigCommand runs a "trmnl-go config" subcommand and returns the exit code
+func runConfigCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, configUsage)
+		return 2
+	}
+
+	command := args[0]
+	reveal := flag.Bool("reveal", false, "Show secrets instead of redacting them (config show/get)")
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, configUsage)
+	}
+	flag.CommandLine.Parse(args[1:])
+	rest := flag.Args()
+
+	var err error
+	switch {
+	case command == "path" && len(rest) == 0:
+		err = configPath()
+	case command == "show" && len(rest) == 0:
+		err = configShow(*reveal)
+	case command == "get" && len(rest) == 1:
+		err = configGet(rest[0], *reveal)
+	case command == "set" && len(rest) == 2:
+		err = configSet(rest[0], rest[1])
+	case command == "unset" && len(rest) == 1:
+		err = configUnset(rest[0])
+	default:
+		fmt.Fprint(os.Stderr, configUsage)
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// configPath prints the config file location
+func configPath() error {
+	path, err := config.Path()
+	if err != nil {
+		return err
+	}
+	fmt.Println(path)
+	return nil
+}
+
+// effectiveConfig loads the configuration the app would run with, along with
+// the source of each setting
+func effectiveConfig() (*config.Config, map[string]string, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sources, err := config.Sources(*profile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	flag.Visit(func(f *flag.Flag) {
+		if key, ok := flagKeys[f.Name]; ok {
+			sources[key] = config.SourceFlag
+		}
+	})
+
+	// Window size follows the model unless set explicitly
+	if cfg.Model != "" {
+		for _, key := range []string{"window_width", "window_height"} {
+			if sources[key] == config.SourceDefault {
+				sources[key] = "model"
+			}
+		}
+	}
+
+	return cfg, sources, nil
+}
+
+// describeSource formats a setting's source for display
+func describeSource(cfg *config.Config, source string) string {
+	if source == config.SourceProfile {
+		return fmt.Sprintf("%s (%s)", source, cfg.Profile())
+	}
+	return source
+}
+
+// configShow prints every setting with its effective value and source
+func configShow(reveal bool) error {
+	cfg, sources, err := effectiveConfig()
+	if err != nil {
+		return err
+	}
+
+	path, err := config.Path()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Config file: %s\n", path)
+	if cfg.Profile() != "" {
+		fmt.Printf("Profile: %s\n", cfg.Profile())
+	}
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+	for _, key := range config.Keys() {
+		value := cfg.DisplayValue(key)
+		if reveal {
+			value = cfg.Value(key)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", key.Name, value, describeSource(cfg, sources[key.Name]))
+	}
+	return w.Flush()
+}
+
+// configGet prints the effective value of a single key
+func configGet(name string, reveal bool) error {
+	key, ok := config.LookupKey(name)
+	if !ok {
+		return fmt.Errorf("unknown key: %s (see 'trmnl-go config show')", name)
+	}
+
+	cfg, _, err := effectiveConfig()
+	if err != nil {
+		return err
+	}
+
+	if reveal {
+		fmt.Println(cfg.Value(key))
+	} else {
+		fmt.Println(cfg.DisplayValue(key))
+	}
+	return nil
+}
+
+// configSet writes a single key to the config file
+func configSet(name, value string) error {
+	changed, err := config.SetValue(*profile, name, value)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Set %s in %s\n", name, describeTarget(changed))
+	return nil
+}
+
+// configUnset removes a single key from the config file
+func configUnset(name string) error {
+	changed, err := config.UnsetValue(*profile, name)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Unset %s in %s\n", name, describeTarget(changed))
+	return nil
+}
+
+// describeTarget names the part of the config file that was changed
+func describeTarget(profileName string) string {
+	if profileName != "" {
+		return "profile " + profileName
+	}
+	return "config file"
+}
+
+// checkConfig validates the configuration the app would run with (file, profile,
+// environment and flags), prints every problem and returns the exit code
+func checkConfig() int {
+	if path, err := config.Path(); err == nil {
+		fmt.Printf("Checking %s\n", path)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	var problems []string
+	if cfg.SchemaVersion > config.SchemaVersion {
+		problems = append(problems, (&config.NewerSchemaError{Version: cfg.SchemaVersion}).Error())
+	}
+	unknown, err := config.UnknownKeys()
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+	for _, key := range unknown {
+		problems = append(problems, fmt.Sprintf("unknown key %q", key))
+	}
+
+	if err := cfg.ValidateSettings(); err != nil {
+		var validationErr *config.ValidationError
+		if errors.As(err, &validationErr) {
+			problems = append(problems, validationErr.Problems...)
+		} else {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	if len(problems) == 0 {
+		if cfg.Profile() != "" {
+			fmt.Printf("Configuration OK (profile %s)\n", cfg.Profile())
+		} else {
+			fmt.Println("Configuration OK")
+		}
+		return 0
+	}
+
+	fmt.Fprintf(os.Stderr, "Found %d problem(s):\n", len(problems))
+	for _, problem := range problems {
+		fmt.Fprintf(os.Stderr, "  - %s\n", problem)
+	}
+	return 1
+}
@@ -0,0 +1,332 @@
+package display
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/semaja2/trmnl-go/config"
+)
+
+// DefaultFramebufferDevice is the framebuffer used when none is given
+const DefaultFramebufferDevice = "/dev/fb0"
+
+// framebufferStatusHeight is the height of the status bar drawn along the bottom edge
+const framebufferStatusHeight = 20
+
+// Bitfield locates one color channel within a pixel
+type Bitfield struct {
+	Offset int // Bit offset from the least significant bit
+	Length int // Number of bits (0 if the channel is absent)
+}
+
+// FramebufferGeometry describes the visible screen and pixel layout of a framebuffer
+type FramebufferGeometry struct {
+	Width        int // Visible width in pixels
+	Height       int // Visible height in pixels
+	XOffset      int // Offset of the visible area within the virtual screen
+	YOffset      int
+	BitsPerPixel int // 8 (grayscale), 16, 24 or 32
+	Stride       int // Bytes per line, which may include padding
+	Grayscale    bool
+	Red          Bitfield
+	Green        Bitfield
+	Blue         Bitfield
+	Alpha        Bitfield
+}
+
+// ParseFramebufferGeometry parses WIDTHxHEIGHTxBPP (e.g. 800x480x32), optionally
+// followed by :STRIDE in bytes, using the usual Linux pixel layouts:
+// 32 is XRGB8888, 24 is RGB888, 16 is RGB565 and 8 is grayscale
+func ParseFramebufferGeometry(s string) (FramebufferGeometry, error) {
+	spec, strideSpec, hasStride := strings.Cut(s, ":")
+	parts := strings.Split(spec, "x")
+	if len(parts) != 3 {
+		return FramebufferGeometry{}, fmt.Errorf("invalid framebuffer geometry %q (expected WIDTHxHEIGHTxBPP, e.g. 800x480x32)", s)
+	}
+
+	values := make([]int, 3)
+	for i, part := range parts {
+		value, err := strconv.Atoi(part)
+		if err != nil || value <= 0 {
+			return FramebufferGeometry{}, fmt.Errorf("invalid framebuffer geometry %q (expected WIDTHxHEIGHTxBPP, e.g. 800x480x32)", s)
+		}
+		values[i] = value
+	}
+
+	g := FramebufferGeometry{Width: values[0], Height: values[1], BitsPerPixel: values[2]}
+	switch g.BitsPerPixel {
+	case 32:
+		g.Red, g.Green, g.Blue, g.Alpha = Bitfield{16, 8}, Bitfield{8, 8}, Bitfield{0, 8}, Bitfield{24, 8}
+	case 24:
+		g.Red, g.Green, g.Blue = Bitfield{16, 8}, Bitfield{8, 8}, Bitfield{0, 8}
+	case 16:
+		g.Red, g.Green, g.Blue = Bitfield{11, 5}, Bitfield{5, 6}, Bitfield{0, 5}
+	case 8:
+		g.Grayscale = true
+	default:
+		return FramebufferGeometry{}, fmt.Errorf("unsupported framebuffer bit depth %d (expected 8, 16, 24 or 32)", g.BitsPerPixel)
+	}
+
+	g.Stride = g.Width * g.BitsPerPixel / 8
+	if hasStride {
+		stride, err := strconv.Atoi(strideSpec)
+		if err != nil || stride < g.Stride {
+			return FramebufferGeometry{}, fmt.Errorf("invalid framebuffer stride %q (at least %d bytes)", strideSpec, g.Stride)
+		}
+		g.Stride = stride
+	}
+	return g, nil
+}
+
+// String formats the geometry as accepted by ParseFramebufferGeometry
+func (g FramebufferGeometry) String() string {
+	return fmt.Sprintf("%dx%dx%d:%d", g.Width, g.Height, g.BitsPerPixel, g.Stride)
+}
+
+// validate checks that the layout can be drawn
+func (g FramebufferGeometry) validate() error {
+	switch g.BitsPerPixel {
+	case 8, 16, 24, 32:
+	default:
+		return fmt.Errorf("unsupported framebuffer bit depth %d (expected 8, 16, 24 or 32)", g.BitsPerPixel)
+	}
+	if g.Width <= 0 || g.Height <= 0 || g.Stride < g.Width*g.BitsPerPixel/8 {
+		return fmt.Errorf("invalid framebuffer geometry %s", g)
+	}
+	return nil
+}
+
+// encode converts an image of the screen size to framebuffer memory
+func (g FramebufferGeometry) encode(img *image.RGBA) []byte {
+	bytesPerPixel := g.BitsPerPixel / 8
+	buf := make([]byte, g.Height*g.Stride)
+	pixel := make([]byte, 4)
+
+	for y := 0; y < g.Height; y++ {
+		line := buf[y*g.Stride:]
+		for x := 0; x < g.Width; x++ {
+			c := img.RGBAAt(x, y)
+
+			var value uint32
+			if g.Grayscale {
+				value = uint32(color.GrayModel.Convert(c).(color.Gray).Y)
+			} else {
+				value = packChannel(c.R, g.Red) | packChannel(c.G, g.Green) | packChannel(c.B, g.Blue) | packChannel(0xff, g.Alpha)
+			}
+
+			binary.LittleEndian.PutUint32(pixel, value)
+			copy(line[(g.XOffset+x)*bytesPerPixel:], pixel[:bytesPerPixel])
+		}
+	}
+	return buf
+}
+
+// packChannel scales an 8-bit channel value into its bitfield
+func packChannel(value uint8, field Bitfield) uint32 {
+	if field.Length == 0 {
+		return 0
+	}
+	v := uint32(value)
+	if field.Length < 8 {
+		v >>= 8 - field.Length
+	} else if field.Length > 8 {
+		v <<= field.Length - 8
+	}
+	return v << field.Offset
+}
+
+// FramebufferWindow draws the display on a Linux framebuffer device (/dev/fbN),
+// for panels driven without X or Wayland
+// A regular file can stand in for the device when the geometry is given explicitly
+type FramebufferWindow struct {
+	mu              sync.Mutex
+	file            *os.File
+	geometry        FramebufferGeometry
+	config          *config.Config
+	verbose         bool
+	imageData       []byte // Last frame, transformed for display
+	status          string
+	closeCh         chan struct{}
+	closeOnce       sync.Once
+	closedCallback  func()
+	refreshCallback func()
+	rotateCallback  func()
+}
+
+// NewFramebufferWindow opens a framebuffer device (default /dev/fb0)
+// Without a geometry (WIDTHxHEIGHTxBPP[:STRIDE]) the layout is read from the device
+func NewFramebufferWindow(cfg *config.Config, path, geometry string, verbose bool) (*FramebufferWindow, error) {
+	if path == "" {
+		path = DefaultFramebufferDevice
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open framebuffer: %w", err)
+	}
+
+	var g FramebufferGeometry
+	if geometry != "" {
+		g, err = ParseFramebufferGeometry(geometry)
+	} else {
+		g, err = queryFramebuffer(file)
+	}
+	if err == nil {
+		err = g.validate()
+	}
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if verbose {
+		fmt.Printf("[Framebuffer] %s: %dx%d, %d bpp, stride %d bytes\n", path, g.Width, g.Height, g.BitsPerPixel, g.Stride)
+	}
+
+	return &FramebufferWindow{
+		file:     file,
+		geometry: g,
+		config:   cfg,
+		verbose:  verbose,
+		status:   "Initializing...",
+		closeCh:  make(chan struct{}),
+	}, nil
+}
+
+// Show draws the current frame and blocks until the window is closed
+func (f *FramebufferWindow) Show() {
+	f.mu.Lock()
+	f.draw()
+	f.mu.Unlock()
+
+	<-f.closeCh
+}
+
+// UpdateImage draws a new frame
+func (f *FramebufferWindow) UpdateImage(imageData []byte) error {
+	if f.verbose {
+		fmt.Printf("[Framebuffer] Decoding image (%d bytes)\n", len(imageData))
+	}
+
+	// Apply transformations (rotation, dark mode, and/or e-paper mode)
+	transformedData, err := applyImageTransformations(imageData, f.config.Rotation, f.config.DarkMode, f.config.EPaperMode)
+	if err != nil {
+		return err
+	}
+	if _, _, err := image.DecodeConfig(bytes.NewReader(transformedData)); err != nil {
+		return fmt.Errorf("failed to decode transformed image: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.imageData = transformedData
+	return f.draw()
+}
+
+// UpdateStatus redraws the frame with a new status bar
+func (f *FramebufferWindow) UpdateStatus(status string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.status = status
+	if err := f.draw(); err != nil && f.verbose {
+		fmt.Printf("[Framebuffer] %v\n", err)
+	}
+}
+
+// draw composes the frame, scaled to fit above the status bar, and writes it out
+// The caller must hold f.mu
+func (f *FramebufferWindow) draw() error {
+	g := f.geometry
+	screen := image.NewRGBA(image.Rect(0, 0, g.Width, g.Height))
+	draw.Draw(screen, screen.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+
+	area := image.Rect(0, 0, g.Width, max(1, g.Height-framebufferStatusHeight))
+	if f.imageData != nil {
+		img, _, err := image.Decode(bytes.NewReader(f.imageData))
+		if err != nil {
+			return fmt.Errorf("failed to decode image: %w", err)
+		}
+		dst := fitRect(img.Bounds(), area)
+		if dst.Size() == img.Bounds().Size() {
+			draw.Draw(screen, dst, img, img.Bounds().Min, draw.Src)
+		} else {
+			xdraw.ApproxBiLinear.Scale(screen, dst, img, img.Bounds(), xdraw.Src, nil)
+		}
+	}
+	drawStatusBar(screen, image.Rect(0, area.Max.Y, g.Width, g.Height), f.status)
+
+	if _, err := f.file.WriteAt(g.encode(screen), int64(g.YOffset*g.Stride)); err != nil {
+		return fmt.Errorf("failed to write framebuffer: %w", err)
+	}
+	return nil
+}
+
+// fitRect returns the largest rectangle with the aspect ratio of src, centered in area
+func fitRect(src, area image.Rectangle) image.Rectangle {
+	scale := min(float64(area.Dx())/float64(src.Dx()), float64(area.Dy())/float64(src.Dy()))
+	width := max(1, int(float64(src.Dx())*scale))
+	height := max(1, int(float64(src.Dy())*scale))
+	min := area.Min.Add(image.Pt((area.Dx()-width)/2, (area.Dy()-height)/2))
+	return image.Rectangle{Min: min, Max: min.Add(image.Pt(width, height))}
+}
+
+// drawStatusBar draws status text in white on a dark gray strip
+func drawStatusBar(img *image.RGBA, bar image.Rectangle, status string) {
+	draw.Draw(img, bar, image.NewUniform(color.Gray{Y: 0x30}), image.Point{}, draw.Src)
+
+	face := basicfont.Face7x13
+	drawer := &font.Drawer{Dst: img, Src: image.NewUniform(color.White), Face: face}
+	width := drawer.MeasureString(status).Round()
+	x := bar.Min.X + max(0, (bar.Dx()-width)/2)
+	y := bar.Min.Y + (bar.Dy()+face.Metrics().Ascent.Round())/2
+	drawer.Dot = fixed.P(x, y)
+	drawer.DrawString(status)
+}
+
+// SetOnClosed sets the callback for when the window is closed
+func (f *FramebufferWindow) SetOnClosed(callback func()) {
+	f.closedCallback = callback
+}
+
+// SetOnRefresh sets the callback for manual refresh
+func (f *FramebufferWindow) SetOnRefresh(callback func()) {
+	f.refreshCallback = callback
+}
+
+// SetOnRotate sets the callback for manual rotate
+func (f *FramebufferWindow) SetOnRotate(callback func()) {
+	f.rotateCallback = callback
+}
+
+// Close releases the framebuffer and makes Show return
+func (f *FramebufferWindow) Close() {
+	f.closeOnce.Do(func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.file.Close()
+		close(f.closeCh)
+	})
+}
+
+// GetApp returns nil, as the framebuffer has no GUI app
+func (f *FramebufferWindow) GetApp() interface{} {
+	return nil
+}
+
+// SetMenuItemsEnabled is a no-op for the framebuffer (there are no menus)
+func (f *FramebufferWindow) SetMenuItemsEnabled(enabled bool) {
+	// No-op - there are no menus or shortcuts
+}
@@ -0,0 +1,103 @@
+//go:build linux
+
+package display
+
+import (
+	"fmt"
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// Framebuffer ioctls (linux/fb.h)
+const (
+	fbiogetVScreenInfo = 0x4600
+	fbiogetFScreenInfo = 0x4602
+)
+
+// fbBitfield mirrors struct fb_bitfield
+type fbBitfield struct {
+	Offset   uint32
+	Length   uint32
+	MSBRight uint32
+}
+
+// fbVarScreenInfo mirrors struct fb_var_screeninfo
+type fbVarScreenInfo struct {
+	XRes, YRes               uint32
+	XResVirtual, YResVirtual uint32
+	XOffset, YOffset         uint32
+	BitsPerPixel             uint32
+	Grayscale                uint32
+	Red, Green, Blue, Transp fbBitfield
+	NonStd                   uint32
+	Activate                 uint32
+	Height, Width            uint32
+	AccelFlags               uint32
+	PixClock                 uint32
+	LeftMargin, RightMargin  uint32
+	UpperMargin, LowerMargin uint32
+	HSyncLen, VSyncLen       uint32
+	Sync                     uint32
+	VMode                    uint32
+	Rotate                   uint32
+	Colorspace               uint32
+	Reserved                 [4]uint32
+}
+
+// fbFixScreenInfo mirrors struct fb_fix_screeninfo (unsigned long fields are uintptr)
+type fbFixScreenInfo struct {
+	ID           [16]byte
+	SmemStart    uintptr
+	SmemLen      uint32
+	Type         uint32
+	TypeAux      uint32
+	Visual       uint32
+	XPanStep     uint16
+	YPanStep     uint16
+	YWrapStep    uint16
+	LineLength   uint32
+	MmioStart    uintptr
+	MmioLen      uint32
+	Accel        uint32
+	Capabilities uint16
+	Reserved     [2]uint16
+}
+
+// queryFramebuffer reads the screen size and pixel layout of a framebuffer device
+func queryFramebuffer(file *os.File) (FramebufferGeometry, error) {
+	var vinfo fbVarScreenInfo
+	if err := fbIoctl(file, fbiogetVScreenInfo, unsafe.Pointer(&vinfo)); err != nil {
+		return FramebufferGeometry{}, fmt.Errorf("not a framebuffer device (give its geometry with -framebuffer-geometry): %w", err)
+	}
+	var finfo fbFixScreenInfo
+	if err := fbIoctl(file, fbiogetFScreenInfo, unsafe.Pointer(&finfo)); err != nil {
+		return FramebufferGeometry{}, fmt.Errorf("failed to read framebuffer info: %w", err)
+	}
+
+	field := func(b fbBitfield) Bitfield {
+		return Bitfield{Offset: int(b.Offset), Length: int(b.Length)}
+	}
+	return FramebufferGeometry{
+		Width:        int(vinfo.XRes),
+		Height:       int(vinfo.YRes),
+		XOffset:      int(vinfo.XOffset),
+		YOffset:      int(vinfo.YOffset),
+		BitsPerPixel: int(vinfo.BitsPerPixel),
+		Stride:       int(finfo.LineLength),
+		Grayscale:    vinfo.Grayscale == 1 || vinfo.BitsPerPixel == 8 && vinfo.Red.Length == 0,
+		Red:          field(vinfo.Red),
+		Green:        field(vinfo.Green),
+		Blue:         field(vinfo.Blue),
+		Alpha:        field(vinfo.Transp),
+	}, nil
+}
+
+func fbIoctl(file *os.File, request uintptr, arg unsafe.Pointer) error {
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, file.Fd(), request, uintptr(arg))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package display
+
+import (
+	"fmt"
+	"os"
+)
+
+// queryFramebuffer is only supported on Linux; elsewhere a file can stand in
+// for the device when its geometry is given
+func queryFramebuffer(file *os.File) (FramebufferGeometry, error) {
+	return FramebufferGeometry{}, fmt.Errorf("framebuffer devices are only supported on Linux (give the geometry with -framebuffer-geometry to draw to a file)")
+}
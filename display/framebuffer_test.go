@@ -0,0 +1,143 @@
+package display
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/semaja2/trmnl-go/config"
+)
+
+// testFrame is a 4x1 PNG with a red, a green, a blue and a white pixel
+func testFrame(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 4, 1))
+	for x, c := range []color.RGBA{{0xff, 0, 0, 0xff}, {0, 0xff, 0, 0xff}, {0, 0, 0xff, 0xff}, {0xff, 0xff, 0xff, 0xff}} {
+		img.SetRGBA(x, 0, c)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestFramebufferWindow(t *testing.T) {
+	// The screens are 4x2: the frame fills the first row and the status bar
+	// (dark gray, with an empty status) the second
+	tests := []struct {
+		geometry string
+		want     [][]byte // Expected bytes of each row, including stride padding
+	}{
+		{
+			geometry: "4x2x16:12", // RGB565, padded to 12 bytes per row
+			want: [][]byte{
+				{0x00, 0xf8, 0xe0, 0x07, 0x1f, 0x00, 0xff, 0xff, 0, 0, 0, 0},
+				{0x86, 0x31, 0x86, 0x31, 0x86, 0x31, 0x86, 0x31, 0, 0, 0, 0},
+			},
+		},
+		{
+			geometry: "4x2x32", // XRGB8888 (alpha set)
+			want: [][]byte{
+				{0x00, 0x00, 0xff, 0xff, 0x00, 0xff, 0x00, 0xff, 0xff, 0x00, 0x00, 0xff, 0xff, 0xff, 0xff, 0xff},
+				{0x30, 0x30, 0x30, 0xff, 0x30, 0x30, 0x30, 0xff, 0x30, 0x30, 0x30, 0xff, 0x30, 0x30, 0x30, 0xff},
+			},
+		},
+		{
+			geometry: "4x2x24", // RGB888
+			want: [][]byte{
+				{0x00, 0x00, 0xff, 0x00, 0xff, 0x00, 0xff, 0x00, 0x00, 0xff, 0xff, 0xff},
+				{0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30},
+			},
+		},
+		{
+			geometry: "4x2x8", // Grayscale (luma)
+			want: [][]byte{
+				{76, 150, 29, 255},
+				{0x30, 0x30, 0x30, 0x30},
+			},
+		},
+	}
+
+	frame := testFrame(t)
+	for _, tt := range tests {
+		t.Run(tt.geometry, func(t *testing.T) {
+			g, err := ParseFramebufferGeometry(tt.geometry)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// A regular file stands in for the device
+			path := filepath.Join(t.TempDir(), "fb")
+			if err := os.WriteFile(path, make([]byte, g.Height*g.Stride), 0600); err != nil {
+				t.Fatal(err)
+			}
+			window, err := NewFramebufferWindow(&config.Config{}, path, tt.geometry, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer window.Close()
+
+			window.UpdateStatus("")
+			if err := window.UpdateImage(frame); err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(data) != g.Height*g.Stride {
+				t.Fatalf("framebuffer holds %d bytes, want %d", len(data), g.Height*g.Stride)
+			}
+			for y, want := range tt.want {
+				if row := data[y*g.Stride : (y+1)*g.Stride]; !bytes.Equal(row, want) {
+					t.Errorf("row %d = % x, want % x", y, row, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseFramebufferGeometry(t *testing.T) {
+	g, err := ParseFramebufferGeometry("800x480x16:1664")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Width != 800 || g.Height != 480 || g.BitsPerPixel != 16 || g.Stride != 1664 || g.Grayscale {
+		t.Errorf("parsed %+v", g)
+	}
+	if g.String() != "800x480x16:1664" {
+		t.Errorf("String() = %q", g)
+	}
+
+	// The stride defaults to width × bytes per pixel
+	if g, err := ParseFramebufferGeometry("800x480x24"); err != nil || g.Stride != 2400 {
+		t.Errorf("800x480x24: stride %d (%v), want 2400", g.Stride, err)
+	}
+	if g, err := ParseFramebufferGeometry("800x480x8"); err != nil || !g.Grayscale {
+		t.Errorf("800x480x8: grayscale %v (%v), want true", g.Grayscale, err)
+	}
+
+	for _, spec := range []string{
+		"",
+		"800x480",
+		"800x480x32x1",
+		"800x0x32",
+		"-800x480x32",
+		"wide x480x32",
+		"800x480x12",      // Unsupported bit depth
+		"800x480x1",       // Unsupported bit depth
+		"800x480x16:1599", // Stride below width × bytes per pixel
+		"800x480x16:",
+		"800x480x16:abc",
+	} {
+		if g, err := ParseFramebufferGeometry(spec); err == nil {
+			t.Errorf("ParseFramebufferGeometry(%q) = %v, want an error", spec, g)
+		}
+	}
+}
@@ -8,6 +8,9 @@ import (
 	"image/png"
 	"math"
 	"math/rand"
+	"net/http"
+
+	_ "github.com/jsummers/gobmp" // Registers the BMP decoder; frames are often 1-bit BMPs
 )
 
 // rotateImage rotates an image by the specified degrees (90, 180, 270)
@@ -217,3 +220,20 @@ func applyEPaperEffect(img image.Image) image.Image {
 
 	return resultRGBA
 }
+
+// encodePNG returns image data as PNG, re-encoding other formats (e.g. BMP frames
+// that needed no transformations)
+func encodePNG(data []byte) ([]byte, error) {
+	if http.DetectContentType(data) == "image/png" {
+		return data, nil
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
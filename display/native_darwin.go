@@ -13,15 +13,26 @@ static NSWindow* mainWindow = nil;
 static NSImageView* imageView = nil;
 static volatile bool refreshRequested = false;
 static volatile bool rotateRequested = false;
+static volatile bool saveRequested = false;
+static volatile bool backRequested = false;
+static volatile bool forwardRequested = false;
+static volatile bool liveRequested = false;
+static volatile bool exportRequested = false;
 
 // Menu item references for enabling/disabling
 static NSMenuItem* refreshMenuItem = nil;
 static NSMenuItem* rotateMenuItem = nil;
+static NSMenuItem* saveMenuItem = nil;
 
 // Window delegate to handle close events and menu actions
 @interface WindowDelegate : NSObject <NSWindowDelegate>
 - (void)refreshAction:(id)sender;
 - (void)rotateAction:(id)sender;
+- (void)saveAction:(id)sender;
+- (void)backAction:(id)sender;
+- (void)forwardAction:(id)sender;
+- (void)liveAction:(id)sender;
+- (void)exportAction:(id)sender;
 @end
 
 @implementation WindowDelegate
@@ -36,6 +47,26 @@ static NSMenuItem* rotateMenuItem = nil;
 - (void)rotateAction:(id)sender {
     rotateRequested = true;
 }
+
+- (void)saveAction:(id)sender {
+    saveRequested = true;
+}
+
+- (void)backAction:(id)sender {
+    backRequested = true;
+}
+
+- (void)forwardAction:(id)sender {
+    forwardRequested = true;
+}
+
+- (void)liveAction:(id)sender {
+    liveRequested = true;
+}
+
+- (void)exportAction:(id)sender {
+    exportRequested = true;
+}
 @end
 
 static WindowDelegate* windowDelegate = nil;
@@ -105,6 +136,14 @@ void updateWindowImage(unsigned char* imageData, int length) {
     });
 }
 
+void resizeWindow(int width, int height) {
+    if (!mainWindow) return;
+
+    dispatch_async(dispatch_get_main_queue(), ^{
+        [mainWindow setContentSize:NSMakeSize(width, height)];
+    });
+}
+
 void setupMenuBar() {
     // Create main menu bar
     NSMenu* mainMenu = [[NSMenu alloc] init];
@@ -124,6 +163,22 @@ void setupMenuBar() {
     // Add app menu to main menu
     [mainMenu addItem:appMenuItem];
 
+    // File menu
+    NSMenu* fileMenu = [[NSMenu alloc] initWithTitle:@"File"];
+    NSMenuItem* fileMenuItem = [[NSMenuItem alloc] init];
+    [fileMenuItem setSubmenu:fileMenu];
+
+    // Save Screenshot menu item (Cmd+S) - initially disabled
+    saveMenuItem = [[NSMenuItem alloc] initWithTitle:@"Save Screenshot"
+                                              action:@selector(saveAction:)
+                                       keyEquivalent:@"s"];
+    [saveMenuItem setTarget:windowDelegate];
+    [saveMenuItem setEnabled:NO]; // Disabled until connected
+    [fileMenu addItem:saveMenuItem];
+
+    // Add file menu to main menu
+    [mainMenu addItem:fileMenuItem];
+
     // View menu
     NSMenu* viewMenu = [[NSMenu alloc] initWithTitle:@"View"];
     NSMenuItem* viewMenuItem = [[NSMenuItem alloc] init];
@@ -157,6 +212,44 @@ void setupMenuBar() {
     // Add view menu to main menu
     [mainMenu addItem:viewMenuItem];
 
+    // History menu
+    NSMenu* historyMenu = [[NSMenu alloc] initWithTitle:@"History"];
+    NSMenuItem* historyMenuItem = [[NSMenuItem alloc] init];
+    [historyMenuItem setSubmenu:historyMenu];
+
+    // Back menu item (Cmd+Left)
+    NSMenuItem* backItem = [[NSMenuItem alloc] initWithTitle:@"Back"
+                                                      action:@selector(backAction:)
+                                               keyEquivalent:[NSString stringWithFormat:@"%C", (unichar)NSLeftArrowFunctionKey]];
+    [backItem setTarget:windowDelegate];
+    [historyMenu addItem:backItem];
+
+    // Forward menu item (Cmd+Right)
+    NSMenuItem* forwardItem = [[NSMenuItem alloc] initWithTitle:@"Forward"
+                                                         action:@selector(forwardAction:)
+                                                  keyEquivalent:[NSString stringWithFormat:@"%C", (unichar)NSRightArrowFunctionKey]];
+    [forwardItem setTarget:windowDelegate];
+    [historyMenu addItem:forwardItem];
+
+    // Return to Live menu item (Cmd+L)
+    NSMenuItem* liveItem = [[NSMenuItem alloc] initWithTitle:@"Return to Live"
+                                                      action:@selector(liveAction:)
+                                               keyEquivalent:@"l"];
+    [liveItem setTarget:windowDelegate];
+    [historyMenu addItem:liveItem];
+
+    [historyMenu addItem:[NSMenuItem separatorItem]];
+
+    // Export as GIF menu item (Cmd+E)
+    NSMenuItem* exportItem = [[NSMenuItem alloc] initWithTitle:@"Export as GIF"
+                                                        action:@selector(exportAction:)
+                                                 keyEquivalent:@"e"];
+    [exportItem setTarget:windowDelegate];
+    [historyMenu addItem:exportItem];
+
+    // Add history menu to main menu
+    [mainMenu addItem:historyMenuItem];
+
     // Set the menu bar
     [NSApp setMainMenu:mainMenu];
 }
@@ -195,6 +288,42 @@ bool checkAndClearRotateRequested() {
     return false;
 }
 
+// Check if save was requested and clear the flag
+bool checkAndClearSaveRequested() {
+    if (saveRequested) {
+        saveRequested = false;
+        return true;
+    }
+    return false;
+}
+
+// Check if a history export was requested and clear the flag
+bool checkAndClearExportRequested() {
+    if (exportRequested) {
+        exportRequested = false;
+        return true;
+    }
+    return false;
+}
+
+// Check if a history action was requested and clear the flag
+// Returns 1 for back, 2 for forward, 3 for return to live and 0 for none
+int checkAndClearHistoryRequested() {
+    if (backRequested) {
+        backRequested = false;
+        return 1;
+    }
+    if (forwardRequested) {
+        forwardRequested = false;
+        return 2;
+    }
+    if (liveRequested) {
+        liveRequested = false;
+        return 3;
+    }
+    return 0;
+}
+
 // Enable or disable the action menu items (for connection state)
 void setMenuItemsEnabled(bool enabled) {
     dispatch_async(dispatch_get_main_queue(), ^{
@@ -204,6 +333,9 @@ void setMenuItemsEnabled(bool enabled) {
         if (rotateMenuItem) {
             [rotateMenuItem setEnabled:enabled];
         }
+        if (saveMenuItem) {
+            [saveMenuItem setEnabled:enabled];
+        }
     });
 }
 */
@@ -223,6 +355,11 @@ type NativeWindow struct {
 	verbose         bool
 	refreshCallback func()
 	rotateCallback  func()
+	saveCallback    func()
+	backCallback    func()
+	forwardCallback func()
+	liveCallback    func()
+	exportCallback  func()
 }
 
 // NewNativeWindow creates a native macOS window
@@ -320,6 +457,28 @@ func (w *NativeWindow) SetOnRefresh(callback func()) {
 						w.rotateCallback()
 					}
 				}
+				if bool(C.checkAndClearSaveRequested()) {
+					if w.saveCallback != nil {
+						w.saveCallback()
+					}
+				}
+				if bool(C.checkAndClearExportRequested()) {
+					if w.exportCallback != nil {
+						w.exportCallback()
+					}
+				}
+				var historyCallback func()
+				switch C.checkAndClearHistoryRequested() {
+				case 1:
+					historyCallback = w.backCallback
+				case 2:
+					historyCallback = w.forwardCallback
+				case 3:
+					historyCallback = w.liveCallback
+				}
+				if historyCallback != nil {
+					historyCallback()
+				}
 			}
 		}()
 	}
@@ -330,6 +489,36 @@ func (w *NativeWindow) SetOnRotate(callback func()) {
 	w.rotateCallback = callback
 }
 
+// SetOnSave sets the callback for saving a screenshot (Cmd+S)
+func (w *NativeWindow) SetOnSave(callback func()) {
+	w.saveCallback = callback
+}
+
+// SetOnExport sets the callback for exporting the frame history as a GIF (Cmd+E)
+func (w *NativeWindow) SetOnExport(callback func()) {
+	w.exportCallback = callback
+}
+
+// SetOnBack sets the callback for showing the previous frame in the history (Cmd+Left)
+func (w *NativeWindow) SetOnBack(callback func()) {
+	w.backCallback = callback
+}
+
+// SetOnForward sets the callback for showing the next frame in the history (Cmd+Right)
+func (w *NativeWindow) SetOnForward(callback func()) {
+	w.forwardCallback = callback
+}
+
+// SetOnLive sets the callback for returning to the live display (Cmd+L)
+func (w *NativeWindow) SetOnLive(callback func()) {
+	w.liveCallback = callback
+}
+
+// Resize changes the window's content size
+func (w *NativeWindow) Resize(width, height int) {
+	C.resizeWindow(C.int(width), C.int(height))
+}
+
 // Close closes the window
 func (w *NativeWindow) Close() {
 	C.stopNativeApp()
@@ -340,7 +529,7 @@ func (w *NativeWindow) GetApp() interface{} {
 	return nil
 }
 
-// SetMenuItemsEnabled enables or disables the action menu items (Refresh and Rotate)
+// SetMenuItemsEnabled enables or disables the action menu items (Refresh, Rotate and Save Screenshot)
 func (w *NativeWindow) SetMenuItemsEnabled(enabled bool) {
 	C.setMenuItemsEnabled(C.bool(enabled))
 	if w.verbose {
@@ -0,0 +1,230 @@
+package display
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/semaja2/trmnl-go/config"
+)
+
+// Screenshot formats (selectable via config or the file extension)
+const (
+	ScreenshotPNG = "png" // The frame as shown, after rotation, dark mode and e-paper processing
+	ScreenshotBMP = "bmp" // The frame as shown, reduced to 1-bit black and white like the device
+	ScreenshotRaw = "raw" // The image bytes exactly as downloaded from the server
+)
+
+// DefaultScreenshotPath is the filename template used when none is configured
+const DefaultScreenshotPath = "~/Pictures/trmnl-{friendly_id}-{timestamp}.{ext}"
+
+// screenshotTimeFormat formats {timestamp} so that names sort chronologically
+const screenshotTimeFormat = "20060102-150405"
+
+// Frame is an image received from the server, with the details used to name it
+type Frame struct {
+	Data       []byte    // Image bytes as downloaded
+	Filename   string    // Name given by the server (TerminalResponse.Filename)
+	FriendlyID string    // Device the frame was fetched for
+	Time       time.Time // When the frame was received
+}
+
+// SaveScreenshot writes a frame to the file named by a template, returning its path
+// The format comes from the template's extension (.png, .bmp or .raw), falling
+// back to format (default png) when the extension is {ext} or unrecognized
+func SaveScreenshot(frame Frame, cfg *config.Config, template, format string) (string, error) {
+	if len(frame.Data) == 0 {
+		return "", fmt.Errorf("no frame to save yet")
+	}
+	if template == "" {
+		template = DefaultScreenshotPath
+	}
+	if f := screenshotFormatForPath(template); f != "" {
+		format = f
+	}
+	if format == "" {
+		format = ScreenshotPNG
+	}
+
+	data, ext, err := EncodeScreenshot(frame, cfg, format)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := expandHome(ExpandScreenshotPath(template, frame, ext))
+	if err != nil {
+		return "", err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create screenshot directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write screenshot: %w", err)
+	}
+	return path, nil
+}
+
+// EncodeScreenshot encodes a frame in the given format, returning the data and
+// its file extension (for raw frames, the extension of the downloaded image type)
+func EncodeScreenshot(frame Frame, cfg *config.Config, format string) ([]byte, string, error) {
+	switch format {
+	case ScreenshotRaw:
+		return frame.Data, rawExtension(frame.Data), nil
+
+	case ScreenshotPNG:
+		// Transformations always produce PNG, except when there are none to apply
+		data, err := applyImageTransformations(frame.Data, cfg.Rotation, cfg.DarkMode, cfg.EPaperMode)
+		if err != nil {
+			return nil, "", err
+		}
+		if data, err = encodePNG(data); err != nil {
+			return nil, "", err
+		}
+		return data, "png", nil
+
+	case ScreenshotBMP:
+		data, err := applyImageTransformations(frame.Data, cfg.Rotation, cfg.DarkMode, cfg.EPaperMode)
+		if err != nil {
+			return nil, "", err
+		}
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to decode image: %w", err)
+		}
+		return encodeMonochromeBMP(img), "bmp", nil
+	}
+	return nil, "", fmt.Errorf("unknown screenshot format %q (expected png, bmp or raw)", format)
+}
+
+// ExpandScreenshotPath fills in a filename template:
+// {timestamp} is when the frame was received, {friendly_id} the device's friendly ID,
+// {filename} the name the server gave the frame and {ext} the format's extension
+func ExpandScreenshotPath(template string, frame Frame, ext string) string {
+	timestamp := frame.Time
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	return strings.NewReplacer(
+		"{timestamp}", timestamp.Format(screenshotTimeFormat),
+		"{friendly_id}", sanitizeFilename(frame.FriendlyID, "unregistered"),
+		"{filename}", sanitizeFilename(frame.Filename, "frame"),
+		"{ext}", ext,
+	).Replace(template)
+}
+
+// screenshotFormatForPath returns the format selected by a template's extension, if any
+func screenshotFormatForPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png":
+		return ScreenshotPNG
+	case ".bmp":
+		return ScreenshotBMP
+	case ".raw":
+		return ScreenshotRaw
+	}
+	return ""
+}
+
+// rawExtension guesses the file extension of downloaded image data
+func rawExtension(data []byte) string {
+	switch http.DetectContentType(data) {
+	case "image/png":
+		return "png"
+	case "image/bmp":
+		return "bmp"
+	case "image/jpeg":
+		return "jpg"
+	case "image/gif":
+		return "gif"
+	case "image/webp":
+		return "webp"
+	}
+	return "bin"
+}
+
+// sanitizeFilename makes a server- or user-supplied value safe to use in a file name
+func sanitizeFilename(value, fallback string) string {
+	value = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, value)
+	value = strings.Trim(value, ".")
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, `~\`) {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, path[1:]), nil
+}
+
+// encodeMonochromeBMP encodes an image as a 1-bit BMP, the format TRMNL devices display
+// Pixels darker than mid-gray become black
+func encodeMonochromeBMP(img image.Image) []byte {
+	const headerSize = 14 + 40 + 2*4 // File header, info header and 2-color palette
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	rowSize := (width + 31) / 32 * 4 // Rows are padded to 4 bytes
+	imageSize := rowSize * height
+
+	var buf bytes.Buffer
+	buf.Grow(headerSize + imageSize)
+
+	// BITMAPFILEHEADER
+	buf.WriteString("BM")
+	binary.Write(&buf, binary.LittleEndian, uint32(headerSize+imageSize))
+	binary.Write(&buf, binary.LittleEndian, uint32(0)) // Reserved
+	binary.Write(&buf, binary.LittleEndian, uint32(headerSize))
+
+	// BITMAPINFOHEADER
+	binary.Write(&buf, binary.LittleEndian, uint32(40))
+	binary.Write(&buf, binary.LittleEndian, int32(width))
+	binary.Write(&buf, binary.LittleEndian, int32(height)) // Positive: rows stored bottom-up
+	binary.Write(&buf, binary.LittleEndian, uint16(1))     // Planes
+	binary.Write(&buf, binary.LittleEndian, uint16(1))     // Bits per pixel
+	binary.Write(&buf, binary.LittleEndian, uint32(0))     // BI_RGB (uncompressed)
+	binary.Write(&buf, binary.LittleEndian, uint32(imageSize))
+	binary.Write(&buf, binary.LittleEndian, int32(2835)) // 72 DPI
+	binary.Write(&buf, binary.LittleEndian, int32(2835))
+	binary.Write(&buf, binary.LittleEndian, uint32(2)) // Colors used
+	binary.Write(&buf, binary.LittleEndian, uint32(0)) // Important colors
+
+	// Palette (BGRA): index 0 black, index 1 white
+	buf.Write([]byte{0, 0, 0, 0, 255, 255, 255, 0})
+
+	row := make([]byte, rowSize)
+	for y := bounds.Max.Y - 1; y >= bounds.Min.Y; y-- {
+		clear(row)
+		for x := 0; x < width; x++ {
+			gray := color.GrayModel.Convert(img.At(bounds.Min.X+x, y)).(color.Gray)
+			if gray.Y >= 128 {
+				row[x/8] |= 0x80 >> (x % 8)
+			}
+		}
+		buf.Write(row)
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,614 @@
+package display
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/draw"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	xdraw "golang.org/x/image/draw"
+
+	"github.com/semaja2/trmnl-go/config"
+)
+
+// Terminal graphics protocols (selectable with -terminal-graphics)
+const (
+	TerminalGraphicsAuto   = "auto"   // Detect from the environment and the terminal's reply
+	TerminalGraphicsKitty  = "kitty"  // Kitty graphics protocol (kitty, Ghostty, Konsole)
+	TerminalGraphicsITerm2 = "iterm2" // iTerm2 inline images (iTerm2, WezTerm)
+	TerminalGraphicsSixel  = "sixel"  // DEC Sixel (foot, mlterm, xterm -ti vt340, Windows Terminal)
+	TerminalGraphicsBlocks = "blocks" // Unicode half blocks, works in any color terminal
+)
+
+// Terminal timing and layout
+const (
+	TerminalQueryTimeout = 500 * time.Millisecond // How long to wait for the terminal to report sixel support
+	defaultCellWidth     = 10                     // Assumed cell size in pixels when the terminal doesn't report it
+	defaultCellHeight    = 20
+	kittyChunkSize       = 4096 // Maximum base64 payload per kitty escape sequence
+	sixelGrayLevels      = 16
+)
+
+// Control keys read from the terminal
+const (
+	keyCtrlC  = 0x03
+	keyCtrlR  = 0x12
+	keyCtrlT  = 0x14
+	keyEscape = 0x1b
+)
+
+// TerminalWindow renders the display in the terminal it runs in, for SSH sessions
+// and headless machines without a window system
+// Frames are drawn with the best graphics protocol the terminal supports, with the
+// status line beneath; Ctrl-R refreshes, Ctrl-T rotates and q or Ctrl-C quits
+// The Left and Right arrows browse the frame history, l returns to live and e
+// exports the history as a GIF
+type TerminalWindow struct {
+	mu              sync.Mutex
+	in              *os.File
+	out             *os.File
+	config          *config.Config
+	verbose         bool
+	graphics        string // Requested protocol (may be auto)
+	protocol        string // Protocol in use once shown
+	truecolor       bool   // Whether half blocks may use 24-bit colors
+	imageData       []byte // Last frame, transformed for display
+	status          string
+	ready           bool // Set once the terminal is set up by Show
+	restore         func()
+	keys            chan byte
+	closeCh         chan struct{}
+	closeOnce       sync.Once
+	closedCallback  func()
+	refreshCallback func()
+	rotateCallback  func()
+	backCallback    func()
+	forwardCallback func()
+	liveCallback    func()
+	exportCallback  func()
+}
+
+// NewTerminalWindow creates a display drawn in the controlling terminal
+// graphics selects the protocol (empty or auto detects it)
+func NewTerminalWindow(cfg *config.Config, graphics string, verbose bool) (*TerminalWindow, error) {
+	switch graphics {
+	case "":
+		graphics = TerminalGraphicsAuto
+	case TerminalGraphicsAuto, TerminalGraphicsKitty, TerminalGraphicsITerm2, TerminalGraphicsSixel, TerminalGraphicsBlocks:
+	default:
+		return nil, fmt.Errorf("unknown terminal graphics %q (expected auto, kitty, iterm2, sixel or blocks)", graphics)
+	}
+
+	in, out, err := openTerminal()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open terminal: %w", err)
+	}
+
+	colorTerm := os.Getenv("COLORTERM")
+	return &TerminalWindow{
+		in:        in,
+		out:       out,
+		config:    cfg,
+		verbose:   verbose,
+		graphics:  graphics,
+		truecolor: colorTerm == "truecolor" || colorTerm == "24bit",
+		status:    "Initializing...",
+		keys:      make(chan byte, 64),
+		closeCh:   make(chan struct{}),
+	}, nil
+}
+
+// Show takes over the terminal and blocks until the window is closed
+func (t *TerminalWindow) Show() {
+	restore, err := makeRaw(t.in, t.out)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[Terminal] Keyboard shortcuts unavailable: %v\n", err)
+		restore = func() {}
+	}
+	go t.readKeys()
+
+	protocol := t.graphics
+	if protocol == TerminalGraphicsAuto {
+		protocol = t.detectProtocol()
+	}
+
+	if t.verbose {
+		fmt.Printf("[Terminal] Using %s graphics\n", protocol)
+	}
+
+	// Switch to the alternate screen and hide the cursor
+	t.out.WriteString("\x1b[?1049h\x1b[?25l")
+
+	t.mu.Lock()
+	t.protocol = protocol
+	t.restore = restore
+	t.ready = true
+	t.draw()
+	t.mu.Unlock()
+
+	resized := make(chan os.Signal, 1)
+	notifyResize(resized)
+
+	for {
+		select {
+		case <-t.closeCh:
+			return
+		case <-resized:
+			t.mu.Lock()
+			t.draw()
+			t.mu.Unlock()
+		case key := <-t.keys:
+			t.handleKey(key)
+		}
+	}
+}
+
+// handleKey runs the action bound to a key
+// Escape sequences other than the Left and Right arrows (terminal replies) are skipped
+func (t *TerminalWindow) handleKey(key byte) {
+	switch key {
+	case keyCtrlR, 'r', 'R':
+		if t.refreshCallback != nil {
+			t.refreshCallback()
+		}
+	case keyCtrlT, 't', 'T':
+		if t.rotateCallback != nil {
+			t.rotateCallback()
+		}
+	case keyCtrlC, 'q', 'Q':
+		if t.closedCallback != nil {
+			t.closedCallback()
+		}
+		t.Close()
+	case 'l', 'L':
+		if t.liveCallback != nil {
+			t.liveCallback()
+		}
+	case 'e', 'E':
+		if t.exportCallback != nil {
+			t.exportCallback()
+		}
+	case keyEscape:
+		var callback func()
+		switch t.readEscapeSequence() {
+		case "[D", "OD":
+			callback = t.backCallback
+		case "[C", "OC":
+			callback = t.forwardCallback
+		}
+		if callback != nil {
+			callback()
+		}
+	}
+}
+
+// readEscapeSequence reads the rest of a CSI, SS3 or OSC sequence and returns it
+// without the escape (e.g. "[D" for the Left arrow)
+func (t *TerminalWindow) readEscapeSequence() string {
+	var seq []byte
+	timeout := time.After(50 * time.Millisecond)
+	for i := 0; ; i++ {
+		select {
+		case key := <-t.keys:
+			seq = append(seq, key)
+			// The introducer ([, O or ]) is followed by parameters up to a final letter or ~
+			if i > 0 && (key >= 'A' && key <= 'Z' || key >= 'a' && key <= 'z' || key == '~' || key == '\a') {
+				return string(seq)
+			}
+		case <-timeout:
+			return string(seq)
+		}
+	}
+}
+
+// readKeys forwards bytes typed in the terminal until it is closed
+func (t *TerminalWindow) readKeys() {
+	buf := make([]byte, 64)
+	for {
+		n, err := t.in.Read(buf)
+		if err != nil {
+			return
+		}
+		for _, key := range buf[:n] {
+			t.keys <- key
+		}
+	}
+}
+
+// detectProtocol picks a graphics protocol from environment variables set by
+// terminals that support one, then asks the terminal whether it supports sixel
+func (t *TerminalWindow) detectProtocol() string {
+	switch {
+	case os.Getenv("KITTY_WINDOW_ID") != "", os.Getenv("TERM") == "xterm-kitty",
+		os.Getenv("TERM") == "xterm-ghostty", os.Getenv("TERM_PROGRAM") == "ghostty":
+		return TerminalGraphicsKitty
+	case os.Getenv("TERM_PROGRAM") == "iTerm.app", os.Getenv("LC_TERMINAL") == "iTerm2",
+		os.Getenv("TERM_PROGRAM") == "WezTerm":
+		return TerminalGraphicsITerm2
+	}
+
+	if t.querySixel() {
+		return TerminalGraphicsSixel
+	}
+	return TerminalGraphicsBlocks
+}
+
+// querySixel sends a Primary Device Attributes request; terminals supporting
+// sixel include attribute 4 in their reply (e.g. ESC [ ? 62 ; 4 ; 22 c)
+func (t *TerminalWindow) querySixel() bool {
+	t.out.WriteString("\x1b[c")
+
+	var reply []byte
+	timeout := time.After(TerminalQueryTimeout)
+	for {
+		select {
+		case key := <-t.keys:
+			reply = append(reply, key)
+			if key != 'c' {
+				continue
+			}
+			start := bytes.Index(reply, []byte("\x1b[?"))
+			if start < 0 {
+				return false
+			}
+			params := strings.TrimSuffix(string(reply[start+3:]), "c")
+			for _, param := range strings.Split(params, ";") {
+				if param == "4" {
+					return true
+				}
+			}
+			return false
+		case <-timeout:
+			return false
+		}
+	}
+}
+
+// UpdateImage draws a new frame
+func (t *TerminalWindow) UpdateImage(imageData []byte) error {
+	if t.verbose {
+		fmt.Printf("[Terminal] Decoding image (%d bytes)\n", len(imageData))
+	}
+
+	// Apply transformations (rotation, dark mode, and/or e-paper mode)
+	transformedData, err := applyImageTransformations(imageData, t.config.Rotation, t.config.DarkMode, t.config.EPaperMode)
+	if err != nil {
+		return err
+	}
+	// The kitty and iTerm2 protocols are sent PNG data
+	if transformedData, err = encodePNG(transformedData); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.imageData = transformedData
+	t.draw()
+	return nil
+}
+
+// UpdateStatus redraws the status line beneath the frame
+func (t *TerminalWindow) UpdateStatus(status string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.status = status
+	if !t.ready {
+		return
+	}
+
+	cols, rows, _, _ := t.size()
+	var b bytes.Buffer
+	t.writeStatus(&b, cols, rows)
+	t.out.Write(b.Bytes())
+}
+
+// size returns the terminal size in cells and pixels (pixels are 0 if unknown)
+func (t *TerminalWindow) size() (cols, rows, width, height int) {
+	cols, rows, width, height, err := terminalSize(t.out)
+	if err != nil || cols <= 0 || rows <= 0 {
+		return 80, 24, 0, 0
+	}
+	return cols, rows, width, height
+}
+
+// draw clears the screen and draws the last frame and the status line
+// The caller must hold t.mu
+func (t *TerminalWindow) draw() {
+	if !t.ready {
+		return
+	}
+	cols, rows, width, height := t.size()
+
+	var b bytes.Buffer
+	b.WriteString("\x1b[H\x1b[2J")
+	if t.protocol == TerminalGraphicsKitty {
+		b.WriteString("\x1b_Ga=d,q=2\x1b\\")
+	}
+
+	if t.imageData != nil && rows > 1 {
+		cellWidth, cellHeight := float64(defaultCellWidth), float64(defaultCellHeight)
+		if width > 0 && height > 0 {
+			cellWidth, cellHeight = float64(width)/float64(cols), float64(height)/float64(rows)
+		}
+		if err := t.writeImage(&b, cols, rows-1, cellWidth, cellHeight); err != nil {
+			t.status = fmt.Sprintf("Display error: %v", err)
+		}
+	}
+
+	t.writeStatus(&b, cols, rows)
+	t.out.Write(b.Bytes())
+}
+
+// writeImage encodes the frame to fit within cols x rows cells, keeping its aspect ratio
+func (t *TerminalWindow) writeImage(b *bytes.Buffer, cols, rows int, cellWidth, cellHeight float64) error {
+	img, _, err := image.Decode(bytes.NewReader(t.imageData))
+	if err != nil {
+		return fmt.Errorf("failed to decode image: %w", err)
+	}
+	bounds := img.Bounds()
+
+	scale := min(float64(cols)*cellWidth/float64(bounds.Dx()), float64(rows)*cellHeight/float64(bounds.Dy()))
+	fitCols := max(1, int(float64(bounds.Dx())*scale/cellWidth))
+	fitRows := max(1, int(float64(bounds.Dy())*scale/cellHeight))
+
+	b.WriteString("\x1b[H")
+	switch t.protocol {
+	case TerminalGraphicsKitty:
+		writeKitty(b, t.imageData, fitCols, fitRows)
+	case TerminalGraphicsITerm2:
+		writeITerm2(b, t.imageData, fitCols, fitRows)
+	case TerminalGraphicsSixel:
+		width := max(1, int(float64(bounds.Dx())*scale))
+		height := max(1, int(float64(bounds.Dy())*scale))
+		writeSixel(b, scaleGray(img, width, height))
+	default:
+		writeHalfBlocks(b, scaleGray(img, fitCols, fitRows*2), t.truecolor)
+	}
+	return nil
+}
+
+// writeStatus writes the status line on the last row, truncated to the terminal width
+func (t *TerminalWindow) writeStatus(b *bytes.Buffer, cols, rows int) {
+	status := []rune(t.status)
+	if len(status) > cols {
+		status = status[:cols]
+	}
+	fmt.Fprintf(b, "\x1b[%d;1H\x1b[2K\x1b[7m%s\x1b[0m", rows, string(status))
+}
+
+// writeKitty sends a PNG with the kitty graphics protocol, scaled to cols x rows cells
+func writeKitty(b *bytes.Buffer, pngData []byte, cols, rows int) {
+	encoded := base64.StdEncoding.EncodeToString(pngData)
+	for i := 0; i < len(encoded); i += kittyChunkSize {
+		chunk := encoded[i:min(i+kittyChunkSize, len(encoded))]
+		more := 0
+		if i+kittyChunkSize < len(encoded) {
+			more = 1
+		}
+		if i == 0 {
+			fmt.Fprintf(b, "\x1b_Gf=100,a=T,q=2,c=%d,r=%d,m=%d;%s\x1b\\", cols, rows, more, chunk)
+		} else {
+			fmt.Fprintf(b, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+}
+
+// writeITerm2 sends a PNG as an iTerm2 inline image, scaled to cols x rows cells
+func writeITerm2(b *bytes.Buffer, pngData []byte, cols, rows int) {
+	fmt.Fprintf(b, "\x1b]1337;File=inline=1;size=%d;width=%d;height=%d;preserveAspectRatio=1:%s\a",
+		len(pngData), cols, rows, base64.StdEncoding.EncodeToString(pngData))
+}
+
+// writeSixel encodes a grayscale image as sixel graphics with 16 gray levels
+func writeSixel(b *bytes.Buffer, img *image.Gray) {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	level := func(x, y int) int {
+		return int(img.GrayAt(x, y).Y) * (sixelGrayLevels - 1) / 255
+	}
+
+	fmt.Fprintf(b, "\x1bPq\"1;1;%d;%d", width, height)
+	for i := 0; i < sixelGrayLevels; i++ {
+		percent := i * 100 / (sixelGrayLevels - 1)
+		fmt.Fprintf(b, "#%d;2;%d;%d;%d", i, percent, percent, percent)
+	}
+
+	row := make([]byte, width)
+	for band := 0; band < height; band += 6 {
+		for color := 0; color < sixelGrayLevels; color++ {
+			used := false
+			for x := 0; x < width; x++ {
+				bits := byte(0)
+				for dy := 0; dy < 6 && band+dy < height; dy++ {
+					if level(x, band+dy) == color {
+						bits |= 1 << dy
+					}
+				}
+				row[x] = '?' + bits
+				used = used || bits != 0
+			}
+			if !used {
+				continue
+			}
+			fmt.Fprintf(b, "#%d", color)
+			writeSixelRun(b, row)
+			b.WriteByte('$')
+		}
+		b.WriteByte('-')
+	}
+	b.WriteString("\x1b\\")
+}
+
+// writeSixelRun writes a row of sixel characters, run-length encoding repeats
+func writeSixelRun(b *bytes.Buffer, row []byte) {
+	for i := 0; i < len(row); {
+		j := i
+		for j < len(row) && row[j] == row[i] {
+			j++
+		}
+		if count := j - i; count > 3 {
+			fmt.Fprintf(b, "!%d%c", count, row[i])
+		} else {
+			b.Write(row[i:j])
+		}
+		i = j
+	}
+}
+
+// writeHalfBlocks draws two pixels per cell with the upper half block character,
+// using the foreground color for the top pixel and the background for the bottom
+func writeHalfBlocks(b *bytes.Buffer, img *image.Gray, truecolor bool) {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	for y := 0; y+1 < height; y += 2 {
+		fmt.Fprintf(b, "\x1b[%d;1H", y/2+1)
+		lastTop, lastBottom := -1, -1
+		for x := 0; x < width; x++ {
+			top, bottom := int(img.GrayAt(x, y).Y), int(img.GrayAt(x, y+1).Y)
+			if top != lastTop {
+				b.WriteString(ansiGray(top, 38, truecolor))
+				lastTop = top
+			}
+			if bottom != lastBottom {
+				b.WriteString(ansiGray(bottom, 48, truecolor))
+				lastBottom = bottom
+			}
+			b.WriteString("▀")
+		}
+		b.WriteString("\x1b[0m")
+	}
+}
+
+// ansiGray returns the SGR sequence setting the foreground (38) or background (48)
+// to a gray level, using the 256-color gray ramp unless 24-bit color is available
+func ansiGray(gray, layer int, truecolor bool) string {
+	if truecolor {
+		return fmt.Sprintf("\x1b[%d;2;%d;%d;%dm", layer, gray, gray, gray)
+	}
+
+	// The ramp (232-255) has no pure black or white; use the cube's corners instead
+	index := 232 + (gray-8)/10
+	switch {
+	case gray < 4:
+		index = 16
+	case gray > 246:
+		index = 231
+	case index > 255:
+		index = 255
+	}
+	return "\x1b[" + strconv.Itoa(layer) + ";5;" + strconv.Itoa(index) + "m"
+}
+
+// scaleGray converts an image to grayscale at the given size
+func scaleGray(img image.Image, width, height int) *image.Gray {
+	gray := image.NewGray(image.Rect(0, 0, width, height))
+	if img.Bounds().Dx() == width && img.Bounds().Dy() == height {
+		draw.Draw(gray, gray.Bounds(), img, img.Bounds().Min, draw.Src)
+		return gray
+	}
+	xdraw.ApproxBiLinear.Scale(gray, gray.Bounds(), img, img.Bounds(), xdraw.Src, nil)
+	return gray
+}
+
+// SetOnClosed sets the callback for when the user quits (q or Ctrl-C)
+func (t *TerminalWindow) SetOnClosed(callback func()) {
+	t.closedCallback = callback
+}
+
+// SetOnRefresh sets the callback for manual refresh (Ctrl-R)
+func (t *TerminalWindow) SetOnRefresh(callback func()) {
+	t.refreshCallback = callback
+}
+
+// SetOnRotate sets the callback for manual rotate (Ctrl-T)
+func (t *TerminalWindow) SetOnRotate(callback func()) {
+	t.rotateCallback = callback
+}
+
+// SetOnExport sets the callback for exporting the frame history as a GIF (e)
+func (t *TerminalWindow) SetOnExport(callback func()) {
+	t.exportCallback = callback
+}
+
+// SetOnBack sets the callback for showing the previous frame in the history (Left arrow)
+func (t *TerminalWindow) SetOnBack(callback func()) {
+	t.backCallback = callback
+}
+
+// SetOnForward sets the callback for showing the next frame in the history (Right arrow)
+func (t *TerminalWindow) SetOnForward(callback func()) {
+	t.forwardCallback = callback
+}
+
+// SetOnLive sets the callback for returning to the live display (l)
+func (t *TerminalWindow) SetOnLive(callback func()) {
+	t.liveCallback = callback
+}
+
+// Close restores the terminal and makes Show return
+func (t *TerminalWindow) Close() {
+	t.closeOnce.Do(func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if t.ready {
+			if t.protocol == TerminalGraphicsKitty {
+				t.out.WriteString("\x1b_Ga=d,q=2\x1b\\")
+			}
+			// Leave the alternate screen and show the cursor again
+			t.out.WriteString("\x1b[0m\x1b[?25h\x1b[?1049l")
+			t.restore()
+		}
+		t.ready = false
+		close(t.closeCh)
+	})
+}
+
+// QuietConsole discards stdout, stderr and log output that would go to the
+// terminal, where it would draw over a terminal display; output redirected to a
+// file or pipe is kept. It returns a function restoring the previous outputs
+// As it replaces os.Stdout and os.Stderr, call it (and the returned function)
+// while no other goroutine writes output
+func QuietConsole() func() {
+	discard, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		return func() {}
+	}
+
+	stdout, stderr, logOutput := os.Stdout, os.Stderr, log.Writer()
+	if isConsole(stdout) {
+		os.Stdout = discard
+	}
+	if isConsole(stderr) {
+		os.Stderr = discard
+		if logOutput == stderr {
+			log.SetOutput(discard)
+		}
+	}
+
+	return func() {
+		os.Stdout, os.Stderr = stdout, stderr
+		log.SetOutput(logOutput)
+		discard.Close()
+	}
+}
+
+// isConsole reports whether f is a terminal rather than a file or pipe
+func isConsole(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// GetApp returns nil, as the terminal window has no GUI app
+func (t *TerminalWindow) GetApp() interface{} {
+	return nil
+}
+
+// SetMenuItemsEnabled is a no-op for the terminal window (shortcuts handled via callbacks)
+func (t *TerminalWindow) SetMenuItemsEnabled(enabled bool) {
+	// No-op - key handlers are already guarded in the callback
+}
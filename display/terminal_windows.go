@@ -0,0 +1,67 @@
+//go:build windows
+
+package display
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// openTerminal opens the console for reading keys and drawing,
+// so standard output can still be redirected (e.g. verbose logs to a file)
+func openTerminal() (*os.File, *os.File, error) {
+	in, err := os.OpenFile("CONIN$", os.O_RDWR, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	out, err := os.OpenFile("CONOUT$", os.O_RDWR, 0)
+	if err != nil {
+		in.Close()
+		return nil, nil, err
+	}
+	return in, out, nil
+}
+
+// makeRaw switches the console to raw virtual terminal input, so keys arrive as
+// they are typed without echo, and enables escape sequence processing for output
+func makeRaw(in, out *os.File) (func(), error) {
+	inHandle, outHandle := windows.Handle(in.Fd()), windows.Handle(out.Fd())
+
+	var inMode, outMode uint32
+	if err := windows.GetConsoleMode(inHandle, &inMode); err != nil {
+		return nil, err
+	}
+	if err := windows.GetConsoleMode(outHandle, &outMode); err != nil {
+		return nil, err
+	}
+
+	raw := inMode&^(windows.ENABLE_ECHO_INPUT|windows.ENABLE_LINE_INPUT|windows.ENABLE_PROCESSED_INPUT) | windows.ENABLE_VIRTUAL_TERMINAL_INPUT
+	if err := windows.SetConsoleMode(inHandle, raw); err != nil {
+		return nil, err
+	}
+	if err := windows.SetConsoleMode(outHandle, outMode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING); err != nil {
+		windows.SetConsoleMode(inHandle, inMode)
+		return nil, err
+	}
+
+	return func() {
+		windows.SetConsoleMode(inHandle, inMode)
+		windows.SetConsoleMode(outHandle, outMode)
+	}, nil
+}
+
+// terminalSize returns the console window size in cells (the pixel size is unknown)
+func terminalSize(out *os.File) (cols, rows, width, height int, err error) {
+	var info windows.ConsoleScreenBufferInfo
+	if err := windows.GetConsoleScreenBufferInfo(windows.Handle(out.Fd()), &info); err != nil {
+		return 0, 0, 0, 0, err
+	}
+	cols = int(info.Window.Right-info.Window.Left) + 1
+	rows = int(info.Window.Bottom-info.Window.Top) + 1
+	return cols, rows, 0, 0, nil
+}
+
+// notifyResize is a no-op: the console has no resize signal, so the next frame
+// picks up the new size
+func notifyResize(ch chan<- os.Signal) {}
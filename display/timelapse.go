@@ -0,0 +1,139 @@
+package display
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/semaja2/trmnl-go/config"
+	"github.com/semaja2/trmnl-go/render"
+)
+
+// DefaultTimelapsePath is the filename template used for exported timelapses
+// Placeholders are as in screenshot paths, filled in from the newest frame
+const DefaultTimelapsePath = "~/Pictures/trmnl-{friendly_id}-{timestamp}.gif"
+
+// Timelapse frame timing limits
+const (
+	minTimelapseDelay = 2     // Shortest GIF frame delay in 1/100s (browsers slow shorter delays down)
+	maxTimelapseDelay = 65535 // Longest delay a GIF frame can hold in 1/100s
+)
+
+// MaxTimelapseFrames is how many of the newest frames a timelapse holds at most,
+// as the whole animation is built in memory
+const MaxTimelapseFrames = 200
+
+// TimelapseFrame is a frame of an animation and how long it stays on screen
+type TimelapseFrame struct {
+	Frame    Frame
+	Duration time.Duration
+}
+
+// SaveTimelapse writes frames as an animated GIF to the file named by a template,
+// returning its path
+// With captions, each frame gets a bar with the time it was received and its filename
+func SaveTimelapse(frames []TimelapseFrame, cfg *config.Config, template string, captions bool) (string, error) {
+	if len(frames) == 0 {
+		return "", fmt.Errorf("no frames in history to export")
+	}
+	if template == "" {
+		template = DefaultTimelapsePath
+	}
+
+	data, err := EncodeTimelapse(frames, cfg, captions)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := expandHome(ExpandScreenshotPath(template, frames[len(frames)-1].Frame, "gif"))
+	if err != nil {
+		return "", err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create timelapse directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write timelapse: %w", err)
+	}
+	return path, nil
+}
+
+// EncodeTimelapse encodes frames as an animated GIF, after the same rotation, dark
+// mode and e-paper processing as the window
+// Frames are reduced to 256 grays, or dithered to a color palette in e-paper mode
+// to keep its tint
+func EncodeTimelapse(frames []TimelapseFrame, cfg *config.Config, captions bool) ([]byte, error) {
+	colors := grayPalette()
+	if cfg.EPaperMode {
+		colors = palette.Plan9
+	}
+
+	anim := &gif.GIF{}
+	for _, frame := range frames {
+		data, err := applyImageTransformations(frame.Frame.Data, cfg.Rotation, cfg.DarkMode, cfg.EPaperMode)
+		if err != nil {
+			return nil, err
+		}
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode frame %q: %w", frame.Frame.Filename, err)
+		}
+
+		bounds := image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy())
+		if captions {
+			rgba := image.NewRGBA(bounds)
+			draw.Draw(rgba, bounds, img, img.Bounds().Min, draw.Src)
+			render.DrawCaption(rgba, timelapseCaption(frame.Frame))
+			img = rgba
+		}
+
+		paletted := image.NewPaletted(bounds, colors)
+		draw.FloydSteinberg.Draw(paletted, bounds, img, img.Bounds().Min)
+		anim.Image = append(anim.Image, paletted)
+		anim.Delay = append(anim.Delay, timelapseDelay(frame.Duration))
+
+		// The canvas fits the largest frame, in case the model changed between frames
+		anim.Config.Width = max(anim.Config.Width, bounds.Dx())
+		anim.Config.Height = max(anim.Config.Height, bounds.Dy())
+	}
+	anim.Config.ColorModel = color.Palette(colors)
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		return nil, fmt.Errorf("failed to encode timelapse: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// timelapseCaption describes a frame, e.g. "2025-01-01 09:30 | plugin-1234"
+func timelapseCaption(frame Frame) string {
+	caption := frame.Time.Local().Format("2006-01-02 15:04")
+	if frame.Filename != "" {
+		caption += " | " + frame.Filename
+	}
+	return caption
+}
+
+// timelapseDelay converts a frame duration to a GIF delay in 1/100s, within the format's limits
+func timelapseDelay(d time.Duration) int {
+	delay := int(d / (10 * time.Millisecond))
+	return min(max(delay, minTimelapseDelay), maxTimelapseDelay)
+}
+
+// grayPalette returns 256 shades of gray, black to white
+func grayPalette() color.Palette {
+	colors := make(color.Palette, 256)
+	for i := range colors {
+		colors[i] = color.Gray{Y: uint8(i)}
+	}
+	return colors
+}
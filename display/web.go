@@ -0,0 +1,366 @@
+package display
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/semaja2/trmnl-go/config"
+)
+
+// DefaultWebAddr is the address the web display listens on when none is given
+const DefaultWebAddr = "localhost:8080"
+
+// webKeepAlive is how often idle event streams are pinged, so proxies keep them open
+const webKeepAlive = 30 * time.Second
+
+//go:embed web/index.html
+var webIndex []byte
+
+// webEvent notifies browsers of a new frame or status
+type webEvent struct {
+	Type   string `json:"type"`             // "frame" or "status"
+	Frame  int    `json:"frame,omitempty"`  // Frame number, appended to /frame.png to bypass caches
+	Status string `json:"status,omitempty"` // Status line text
+}
+
+// webAction is sent by browsers over the WebSocket
+type webAction struct {
+	Action string `json:"action"` // "refresh" or "rotate"
+}
+
+// WebWindow serves the display to browsers: an embedded page shows the current
+// frame and status line, and is notified of changes over a WebSocket (or
+// Server-Sent Events as a fallback); its refresh and rotate buttons act like
+// the keyboard shortcuts of the window
+type WebWindow struct {
+	mu              sync.Mutex
+	server          *http.Server
+	listener        net.Listener
+	config          *config.Config
+	verbose         bool
+	imageData       []byte // Last frame, transformed for display
+	frame           int
+	status          string
+	clients         map[chan webEvent]struct{}
+	closeCh         chan struct{}
+	closeOnce       sync.Once
+	closedCallback  func()
+	refreshCallback func()
+	rotateCallback  func()
+}
+
+// NewWebWindow listens on addr (default localhost:8080) for browsers
+// The listener is opened synchronously so address errors are reported to the caller
+func NewWebWindow(cfg *config.Config, addr string, verbose bool) (*WebWindow, error) {
+	if addr == "" {
+		addr = DefaultWebAddr
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	w := &WebWindow{
+		listener: listener,
+		config:   cfg,
+		verbose:  verbose,
+		status:   "Initializing...",
+		clients:  make(map[chan webEvent]struct{}),
+		closeCh:  make(chan struct{}),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", w.handleIndex)
+	mux.HandleFunc("/frame.png", w.handleFrame)
+	mux.HandleFunc("/events", w.handleEvents)
+	mux.Handle("/ws", websocket.Server{Handshake: checkWebSocketOrigin, Handler: w.handleWebSocket})
+	mux.HandleFunc("/refresh", w.handleAction(func() func() { return w.refreshCallback }))
+	mux.HandleFunc("/rotate", w.handleAction(func() func() { return w.rotateCallback }))
+
+	w.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return w, nil
+}
+
+// URL returns the address browsers should open
+func (w *WebWindow) URL() string {
+	host, port, err := net.SplitHostPort(w.listener.Addr().String())
+	if err != nil {
+		return "http://" + w.listener.Addr().String() + "/"
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, port) + "/"
+}
+
+// Show serves browsers until the window is closed
+func (w *WebWindow) Show() {
+	fmt.Printf("[Web] Display available at %s\n", w.URL())
+
+	go func() {
+		if err := w.server.Serve(w.listener); err != nil && err != http.ErrServerClosed {
+			fmt.Printf("[Web] Server error: %v\n", err)
+			if w.closedCallback != nil {
+				w.closedCallback()
+			}
+			w.Close()
+		}
+	}()
+
+	<-w.closeCh
+}
+
+// UpdateImage publishes a new frame to connected browsers
+func (w *WebWindow) UpdateImage(imageData []byte) error {
+	if w.verbose {
+		fmt.Printf("[Web] Decoding image (%d bytes)\n", len(imageData))
+	}
+
+	// Apply transformations (rotation, dark mode, and/or e-paper mode)
+	transformedData, err := applyImageTransformations(imageData, w.config.Rotation, w.config.DarkMode, w.config.EPaperMode)
+	if err != nil {
+		return err
+	}
+	// Frames are served as PNG, which every browser displays
+	if transformedData, err = encodePNG(transformedData); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.imageData = transformedData
+	w.frame++
+	w.broadcast(webEvent{Type: "frame", Frame: w.frame})
+	return nil
+}
+
+// UpdateStatus publishes a new status line to connected browsers
+func (w *WebWindow) UpdateStatus(status string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.status = status
+	w.broadcast(webEvent{Type: "status", Status: status})
+}
+
+// broadcast sends an event to every client; clients too slow to keep up miss it,
+// which is harmless as every event carries the complete current state
+// The caller must hold w.mu
+func (w *WebWindow) broadcast(event webEvent) {
+	for client := range w.clients {
+		select {
+		case client <- event:
+		default:
+		}
+	}
+}
+
+// subscribe registers a client, queueing the current status and frame for it
+func (w *WebWindow) subscribe() chan webEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	client := make(chan webEvent, 16)
+	client <- webEvent{Type: "status", Status: w.status}
+	if w.imageData != nil {
+		client <- webEvent{Type: "frame", Frame: w.frame}
+	}
+	w.clients[client] = struct{}{}
+	return client
+}
+
+func (w *WebWindow) unsubscribe(client chan webEvent) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.clients, client)
+}
+
+// handleIndex serves the embedded page
+func (w *WebWindow) handleIndex(rw http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(rw, r)
+		return
+	}
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	rw.Write(webIndex)
+}
+
+// handleFrame serves the current frame as PNG
+func (w *WebWindow) handleFrame(rw http.ResponseWriter, r *http.Request) {
+	w.mu.Lock()
+	data := w.imageData
+	w.mu.Unlock()
+
+	if data == nil {
+		http.Error(rw, "no frame yet", http.StatusNotFound)
+		return
+	}
+	rw.Header().Set("Content-Type", "image/png")
+	rw.Header().Set("Cache-Control", "no-store")
+	rw.Write(data)
+}
+
+// handleEvents streams events to the browser as Server-Sent Events
+func (w *WebWindow) handleEvents(rw http.ResponseWriter, r *http.Request) {
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		http.Error(rw, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.Header().Set("Connection", "keep-alive")
+
+	client := w.subscribe()
+	defer w.unsubscribe(client)
+
+	keepAlive := time.NewTicker(webKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case event := <-client:
+			data, err := json.Marshal(event)
+			if err != nil {
+				return
+			}
+			fmt.Fprintf(rw, "data: %s\n\n", data)
+		case <-keepAlive.C:
+			fmt.Fprint(rw, ": ping\n\n")
+		case <-r.Context().Done():
+			return
+		case <-w.closeCh:
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// handleWebSocket sends events to the browser and runs the actions it sends back
+func (w *WebWindow) handleWebSocket(ws *websocket.Conn) {
+	client := w.subscribe()
+	defer w.unsubscribe(client)
+
+	// Read actions until the browser disconnects
+	disconnected := make(chan struct{})
+	go func() {
+		defer close(disconnected)
+		for {
+			var action webAction
+			if err := websocket.JSON.Receive(ws, &action); err != nil {
+				return
+			}
+			switch action.Action {
+			case "refresh":
+				w.runCallback(w.refreshCallback)
+			case "rotate":
+				w.runCallback(w.rotateCallback)
+			}
+		}
+	}()
+
+	for {
+		select {
+		case event := <-client:
+			if err := websocket.JSON.Send(ws, event); err != nil {
+				return
+			}
+		case <-disconnected:
+			return
+		case <-w.closeCh:
+			ws.Close()
+			return
+		}
+	}
+}
+
+// handleAction runs a callback for a POST from the page (used without a WebSocket)
+func (w *WebWindow) handleAction(callback func() func()) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			rw.Header().Set("Allow", http.MethodPost)
+			http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !sameOrigin(r) {
+			http.Error(rw, "cross-origin request refused", http.StatusForbidden)
+			return
+		}
+		w.runCallback(callback())
+		rw.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (w *WebWindow) runCallback(callback func()) {
+	if callback != nil {
+		callback()
+	}
+}
+
+// sameOrigin reports whether a browser request comes from the display's own page,
+// so other sites can't trigger refreshes or rotations
+// Requests without an Origin header (e.g. curl) are allowed
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+// checkWebSocketOrigin rejects WebSocket connections from other sites
+func checkWebSocketOrigin(cfg *websocket.Config, r *http.Request) error {
+	if !sameOrigin(r) {
+		return fmt.Errorf("cross-origin WebSocket refused")
+	}
+	return nil
+}
+
+// SetOnClosed sets the callback for when the server stops unexpectedly
+func (w *WebWindow) SetOnClosed(callback func()) {
+	w.closedCallback = callback
+}
+
+// SetOnRefresh sets the callback for the page's refresh button
+func (w *WebWindow) SetOnRefresh(callback func()) {
+	w.refreshCallback = callback
+}
+
+// SetOnRotate sets the callback for the page's rotate button
+func (w *WebWindow) SetOnRotate(callback func()) {
+	w.rotateCallback = callback
+}
+
+// Close stops the server, disconnecting browsers, and makes Show return
+func (w *WebWindow) Close() {
+	w.closeOnce.Do(func() {
+		close(w.closeCh)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		w.server.Shutdown(ctx)
+	})
+}
+
+// GetApp returns nil, as the web display has no GUI app
+func (w *WebWindow) GetApp() interface{} {
+	return nil
+}
+
+// SetMenuItemsEnabled is a no-op for the web display (actions handled via callbacks)
+func (w *WebWindow) SetMenuItemsEnabled(enabled bool) {
+	// No-op - actions are already guarded in the callback
+}
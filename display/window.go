@@ -28,17 +28,43 @@ type Window struct {
 	verbose         bool
 	refreshCallback func()
 	rotateCallback  func()
+	saveCallback    func()
+	backCallback    func()
+	forwardCallback func()
+	liveCallback    func()
+	exportCallback  func()
+	ownsApp         bool // Whether Show runs the app's event loop
 }
 
 // NewWindow creates a new display window
 func NewWindow(cfg *config.Config, verbose bool) *Window {
+	return newWindow(app.New(), cfg, verbose, true)
+}
+
+// NewWindows creates one display window per config, sharing a single Fyne app
+// Showing the first window runs the event loop; closing it quits the app
+func NewWindows(cfgs []*config.Config, verbose bool) []*Window {
+	fyneApp := app.New()
+	windows := make([]*Window, len(cfgs))
+	for i, cfg := range cfgs {
+		windows[i] = newWindow(fyneApp, cfg, verbose, i == 0)
+	}
+	return windows
+}
+
+func newWindow(fyneApp fyne.App, cfg *config.Config, verbose bool, ownsApp bool) *Window {
 	w := &Window{
-		app:     app.New(),
+		app:     fyneApp,
 		config:  cfg,
 		verbose: verbose,
+		ownsApp: ownsApp,
 	}
 
-	w.window = w.app.NewWindow("TRMNL Virtual Display")
+	title := "TRMNL Virtual Display"
+	if name := cfg.DeviceName(); name != "" {
+		title += " - " + name
+	}
+	w.window = w.app.NewWindow(title)
 
 	// Set fullscreen or windowed mode
 	if cfg.Fullscreen {
@@ -49,7 +75,9 @@ func NewWindow(cfg *config.Config, verbose bool) *Window {
 	}
 
 	// Set as master window for proper app behavior (shows dock icon on macOS)
-	w.window.SetMaster()
+	if ownsApp {
+		w.window.SetMaster()
+	}
 
 	// Create image widget
 	w.imageWidget = canvas.NewImageFromImage(nil)
@@ -92,12 +120,64 @@ func NewWindow(cfg *config.Config, verbose bool) *Window {
 		}
 	})
 
+	// Cmd+S / Ctrl+S to save a screenshot
+	w.window.Canvas().AddShortcut(&desktop.CustomShortcut{
+		KeyName:  fyne.KeyS,
+		Modifier: fyne.KeyModifierControl | fyne.KeyModifierSuper,
+	}, func(shortcut fyne.Shortcut) {
+		if w.saveCallback != nil {
+			w.saveCallback()
+		}
+	})
+
+	// Cmd+E / Ctrl+E to export the frame history as an animated GIF
+	w.window.Canvas().AddShortcut(&desktop.CustomShortcut{
+		KeyName:  fyne.KeyE,
+		Modifier: fyne.KeyModifierControl | fyne.KeyModifierSuper,
+	}, func(shortcut fyne.Shortcut) {
+		if w.exportCallback != nil {
+			w.exportCallback()
+		}
+	})
+
+	// Cmd+Left / Ctrl+Left and Cmd+Right / Ctrl+Right to browse the frame history
+	w.window.Canvas().AddShortcut(&desktop.CustomShortcut{
+		KeyName:  fyne.KeyLeft,
+		Modifier: fyne.KeyModifierControl | fyne.KeyModifierSuper,
+	}, func(shortcut fyne.Shortcut) {
+		if w.backCallback != nil {
+			w.backCallback()
+		}
+	})
+	w.window.Canvas().AddShortcut(&desktop.CustomShortcut{
+		KeyName:  fyne.KeyRight,
+		Modifier: fyne.KeyModifierControl | fyne.KeyModifierSuper,
+	}, func(shortcut fyne.Shortcut) {
+		if w.forwardCallback != nil {
+			w.forwardCallback()
+		}
+	})
+
+	// Cmd+L / Ctrl+L to return to the live display
+	w.window.Canvas().AddShortcut(&desktop.CustomShortcut{
+		KeyName:  fyne.KeyL,
+		Modifier: fyne.KeyModifierControl | fyne.KeyModifierSuper,
+	}, func(shortcut fyne.Shortcut) {
+		if w.liveCallback != nil {
+			w.liveCallback()
+		}
+	})
+
 	return w
 }
 
 // Show displays the window
+// For the window owning the app, this blocks until the window closes
 func (w *Window) Show() {
 	w.window.Show()
+	if !w.ownsApp {
+		return
+	}
 	// Start the main event loop (blocks until window closes)
 	w.app.Run()
 }
@@ -170,6 +250,43 @@ func (w *Window) SetOnRotate(callback func()) {
 	w.rotateCallback = callback
 }
 
+// SetOnSave sets the callback for saving a screenshot (Cmd+S / Ctrl+S)
+func (w *Window) SetOnSave(callback func()) {
+	w.saveCallback = callback
+}
+
+// SetOnExport sets the callback for exporting the frame history as a GIF (Cmd+E / Ctrl+E)
+func (w *Window) SetOnExport(callback func()) {
+	w.exportCallback = callback
+}
+
+// SetOnBack sets the callback for showing the previous frame in the history (Cmd+Left / Ctrl+Left)
+func (w *Window) SetOnBack(callback func()) {
+	w.backCallback = callback
+}
+
+// SetOnForward sets the callback for showing the next frame in the history (Cmd+Right / Ctrl+Right)
+func (w *Window) SetOnForward(callback func()) {
+	w.forwardCallback = callback
+}
+
+// SetOnLive sets the callback for returning to the live display (Cmd+L / Ctrl+L)
+func (w *Window) SetOnLive(callback func()) {
+	w.liveCallback = callback
+}
+
+// Resize changes the window size (ignored in fullscreen mode)
+func (w *Window) Resize(width, height int) {
+	if w.config.Fullscreen {
+		return
+	}
+	size := fyne.NewSize(float32(width), float32(height))
+	fyne.Do(func() {
+		w.imageWidget.SetMinSize(size)
+		w.window.Resize(size)
+	})
+}
+
 // Close closes the window
 func (w *Window) Close() {
 	w.window.Close()
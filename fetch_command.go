@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/semaja2/trmnl-go/api"
+	"github.com/semaja2/trmnl-go/config"
+	"github.com/semaja2/trmnl-go/display"
+)
+
+const fetchUsage = `Usage: trmnl-go fetch [flags]
+
+Fetches the current screen once (registering the device first if needed),
+prints the server's response as JSON and exits non-zero on failure. With -out,
+the image is also saved: .png and .bmp files get the configured rotation,
+dark mode and e-paper processing, .raw files the image as downloaded.
+
+Flags are the same as for trmnl-go (e.g. -profile staging, -mirror), plus:
+  -out string   File to save the image to (placeholders as in screenshot_path)
+`
+
+// runFetchCommand runs "trmnl-go fetch" and returns the exit code
+func runFetchCommand(args []string) int {
+	out := flag.String("out", "", "File to save the image to (placeholders as in screenshot_path)")
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, fetchUsage)
+	}
+	flag.CommandLine.Parse(args)
+	if flag.NArg() > 0 {
+		fmt.Fprint(os.Stderr, fetchUsage)
+		return 2
+	}
+
+	cfg, err := loadConfig()
+	if err == nil {
+		err = cfg.ValidateSettings()
+	}
+	if err == nil {
+		cfg, err = singleDeviceConfig(cfg)
+	}
+	var finishSession func()
+	if err == nil {
+		finishSession, err = startSession(cfg.Verbose)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	defer finishSession()
+
+	termResp, frame, err := fetchOnce(cfg)
+	if termResp != nil {
+		data, jsonErr := json.MarshalIndent(termResp, "", "  ")
+		if jsonErr != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", jsonErr)
+			return 1
+		}
+		fmt.Println(string(data))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if *out != "" {
+		if _, err := display.SaveScreenshot(frame, cfg, *out, cfg.ScreenshotFormat); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+	return 0
+}
+
+// singleDeviceConfig returns the config of the only device, for one-shot commands
+func singleDeviceConfig(cfg *config.Config) (*config.Config, error) {
+	deviceConfigs, err := expandDevices(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid device configuration: %w", err)
+	}
+	if len(deviceConfigs) > 1 {
+		return nil, fmt.Errorf("only a single device can be fetched (%d configured)", len(deviceConfigs))
+	}
+	return deviceConfigs[0], nil
+}
+
+// fetchOnce fetches the current screen and its image, registering the device first if needed
+// The response is returned whenever the server sent one, even on failure
+func fetchOnce(cfg *config.Config) (*api.TerminalResponse, display.Frame, error) {
+	assignDeviceID(cfg)
+
+	metricsSource, err := newMetricsProvider(cfg, *netInterface)
+	if err != nil {
+		return nil, display.Frame{}, fmt.Errorf("invalid metrics provider: %w", err)
+	}
+	client := api.NewClient(cfg, metricsSource, cfg.Verbose)
+
+	// Register the device first if needed, as the window would
+	if cfg.APIKey == "" || *setup {
+		setupResp, err := client.FetchSetup(cfg.DeviceID)
+		if err != nil {
+			return nil, display.Frame{}, fmt.Errorf("setup failed: %w", err)
+		}
+		cfg.APIKey = setupResp.APIKey
+		cfg.FriendlyID = setupResp.FriendlyID
+		if !replaying() {
+			if err := cfg.SaveSetupInfo(); err != nil {
+				log.Printf("Warning: Could not save config: %v", err)
+			}
+		}
+		client = api.NewClient(cfg, metricsSource, cfg.Verbose)
+	}
+
+	var termResp *api.TerminalResponse
+	if cfg.MirrorMode {
+		termResp, err = client.FetchCurrentScreen()
+	} else {
+		termResp, err = client.FetchDisplay()
+	}
+	if err != nil {
+		return nil, display.Frame{}, fmt.Errorf("failed to fetch display: %w", err)
+	}
+	if termResp.Error != "" {
+		return termResp, display.Frame{}, fmt.Errorf("API returned error: %s", termResp.Error)
+	}
+
+	imageData, err := client.FetchImage(termResp.ImageURL)
+	if err != nil {
+		return termResp, display.Frame{}, fmt.Errorf("failed to fetch image: %w", err)
+	}
+
+	frame := display.Frame{
+		Data:       imageData,
+		Filename:   termResp.Filename,
+		FriendlyID: cfg.FriendlyID,
+		Time:       time.Now(),
+	}
+	return termResp, frame, nil
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/semaja2/trmnl-go/display"
+	"github.com/semaja2/trmnl-go/history"
+)
+
+// History navigation actions (sent on App.navigateCh)
+const (
+	historyBack = iota
+	historyForward
+	historyLive
+)
+
+// windowNavigator is implemented by windows with history navigation actions
+type windowNavigator interface {
+	SetOnBack(func())
+	SetOnForward(func())
+	SetOnLive(func())
+}
+
+// openHistory creates the frame history for the app's device
+// Frames are kept in memory only when replaying a session or if the cache
+// directory is unavailable
+func (a *App) openHistory() {
+	if a.config.HistorySize <= 0 {
+		return
+	}
+
+	// Replayed frames belong to another session and must not replace the saved history
+	if replaying() {
+		a.history, _ = history.Open("", a.config.HistorySize)
+		if a.verbose {
+			fmt.Printf("[App] Frame history kept in memory while replaying (%d frames)\n", a.config.HistorySize)
+		}
+		return
+	}
+
+	dir, err := history.Dir(a.config.Profile(), a.config.DeviceName())
+	if err == nil {
+		a.history, err = history.Open(dir, a.config.HistorySize)
+	}
+	if err != nil {
+		log.Printf("Warning: Frame history kept in memory only: %v", err)
+		a.history, _ = history.Open("", a.config.HistorySize)
+		return
+	}
+
+	if a.verbose {
+		fmt.Printf("[App] Frame history: %s (%d frames)\n", dir, a.history.Len())
+	}
+}
+
+// connectNavigation wires the window's history actions to the app
+func (a *App) connectNavigation() {
+	navigator, ok := a.window.(windowNavigator)
+	if !ok || a.history == nil {
+		return
+	}
+
+	navigate := func(action int) func() {
+		return func() {
+			// Non-blocking send to navigate channel
+			select {
+			case a.navigateCh <- action:
+			default:
+				// Channel full, navigation already pending
+			}
+		}
+	}
+	navigator.SetOnBack(navigate(historyBack))
+	navigator.SetOnForward(navigate(historyForward))
+	navigator.SetOnLive(navigate(historyLive))
+}
+
+// recordFrame adds a fetched frame to the history
+func (a *App) recordFrame(frame display.Frame, refreshRate int) {
+	if a.history == nil {
+		return
+	}
+
+	entry, err := a.history.Add(frame, refreshRate)
+	if err != nil {
+		log.Printf("Failed to save frame history: %v", err)
+	}
+	a.liveSeq = entry.Seq
+
+	// Keep the indicator current, as the position shifts when old frames are dropped
+	if a.viewingHistory() {
+		a.showHistoryStatus()
+	}
+}
+
+// navigateHistory steps through the history, or returns to the live display
+func (a *App) navigateHistory(action int) {
+	if action == historyLive {
+		a.returnToLive()
+		return
+	}
+
+	delta := -1
+	if action == historyForward {
+		delta = 1
+	}
+
+	// Steps start from the frame being viewed, or the live frame
+	var entry history.Entry
+	var ok bool
+	switch {
+	case a.viewingHistory():
+		entry, ok = a.history.Step(a.viewed.Seq, delta)
+		if !ok && action == historyBack {
+			if position, _ := a.history.Position(a.viewed.Seq); position == 0 {
+				// The frame being viewed was dropped from the history; go to the oldest left
+				entry, ok = a.history.Oldest()
+			}
+		}
+	case action == historyBack && a.liveSeq == 0:
+		// Nothing fetched yet: start from the newest frame of an earlier run
+		entry, ok = a.history.Latest()
+	case action == historyBack:
+		entry, ok = a.history.Step(a.liveSeq, -1)
+	}
+	if !ok {
+		if a.verbose {
+			fmt.Println("[App] No more frames in history")
+		}
+		return
+	}
+
+	if entry.Seq == a.liveSeq {
+		// Stepping forward onto the live frame ends browsing
+		a.returnToLive()
+		return
+	}
+
+	a.viewed = entry
+	if err := a.window.UpdateImage(entry.Frame.Data); err != nil {
+		log.Printf("Failed to display history frame: %v", err)
+	}
+	a.showHistoryStatus()
+
+	if a.verbose {
+		fmt.Printf("[App] Viewing history frame %d (%s)\n", entry.Seq, entry.Frame.Filename)
+	}
+}
+
+// returnToLive shows the latest frame and status again after browsing the history
+func (a *App) returnToLive() {
+	if !a.viewingHistory() {
+		return
+	}
+
+	a.viewed = history.Entry{}
+	if a.lastFrame.Data != nil {
+		if err := a.window.UpdateImage(a.lastFrame.Data); err != nil {
+			log.Printf("Failed to display live frame: %v", err)
+		}
+	}
+	a.window.UpdateStatus(a.liveStatus)
+
+	if a.verbose {
+		fmt.Println("[App] Returned to live display")
+	}
+}
+
+// viewingHistory reports whether a past frame is being shown instead of the live display
+func (a *App) viewingHistory() bool {
+	return a.viewed.Seq != 0
+}
+
+// shownFrame returns the frame on screen: the history frame being viewed, or the live frame
+func (a *App) shownFrame() display.Frame {
+	if a.viewingHistory() {
+		return a.viewed.Frame
+	}
+	return a.lastFrame
+}
+
+// setStatus shows the status of the live display
+// While browsing the history it is kept for when the user returns to live
+func (a *App) setStatus(status string) {
+	a.liveStatus = status
+	if !a.viewingHistory() {
+		a.window.UpdateStatus(status)
+	}
+}
+
+// showHistoryStatus shows which history frame is being viewed
+// e.g. "[History 3/20] plugin-1234 | 09:30:00 | refresh 900s"
+func (a *App) showHistoryStatus() {
+	position, total := a.history.Position(a.viewed.Seq)
+
+	frame := a.viewed.Frame
+	received := frame.Time.Local().Format("15:04:05")
+	if frame.Time.Local().Format(time.DateOnly) != time.Now().Format(time.DateOnly) {
+		received = frame.Time.Local().Format("Jan 2 15:04:05")
+	}
+
+	status := fmt.Sprintf("[History %d/%d] %s | %s", position, total, frame.Filename, received)
+	if position == 0 {
+		status = fmt.Sprintf("[History] %s | %s (no longer kept)", frame.Filename, received)
+	}
+	if a.viewed.RefreshRate > 0 {
+		status += fmt.Sprintf(" | refresh %ds", a.viewed.RefreshRate)
+	}
+	a.window.UpdateStatus(status)
+}
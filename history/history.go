@@ -0,0 +1,299 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/semaja2/trmnl-go/display"
+)
+
+// MemoryFrames is how many of the newest frames are held in memory; older
+// frames are read back from disk when viewed
+const MemoryFrames = 10
+
+// Entry is a frame kept in the history
+type Entry struct {
+	Seq         int           // Sequence number, increasing with every frame (starting at 1)
+	Frame       display.Frame // Image and naming details (Data is nil until loaded)
+	RefreshRate int           // Seconds the server asked to wait for the next frame
+	saved       bool          // Whether the image is on disk, so it can be dropped from memory
+}
+
+// record is the metadata stored next to each frame on disk
+type record struct {
+	Seq         int       `json:"seq"`
+	Filename    string    `json:"filename,omitempty"`
+	FriendlyID  string    `json:"friendly_id,omitempty"`
+	Time        time.Time `json:"time"`
+	RefreshRate int       `json:"refresh_rate"`
+}
+
+// History is a bounded list of recently fetched frames, oldest first
+// With a directory, frames are also written to disk, so they survive restarts
+// and only the newest are kept in memory
+// All methods are safe for concurrent use
+type History struct {
+	mu      sync.Mutex
+	dir     string // Empty keeps frames in memory only
+	size    int
+	entries []*Entry
+}
+
+// Dir returns the directory holding the history of a device, in the user's cache directory
+// profile and device are the active profile and device names (empty if unused)
+func Dir(profile, device string) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get cache directory: %w", err)
+	}
+
+	var parts []string
+	for _, part := range []string{profile, device} {
+		if part != "" {
+			parts = append(parts, sanitize(part))
+		}
+	}
+	name := "default"
+	if len(parts) > 0 {
+		name = strings.Join(parts, "-")
+	}
+	return filepath.Join(cacheDir, "trmnl", "history", name), nil
+}
+
+// Open creates a history of up to size frames, loading the frames saved in dir
+// An empty dir keeps every frame in memory instead
+func Open(dir string, size int) (*History, error) {
+	h := &History{dir: dir, size: size}
+	if dir == "" {
+		return h, nil
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list history: %w", err)
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var r record
+		if err := json.Unmarshal(data, &r); err != nil || r.Seq <= 0 {
+			continue
+		}
+		if _, err := os.Stat(h.framePath(r.Seq)); err != nil {
+			continue
+		}
+		h.entries = append(h.entries, &Entry{
+			Seq: r.Seq,
+			Frame: display.Frame{
+				Filename:   r.Filename,
+				FriendlyID: r.FriendlyID,
+				Time:       r.Time,
+			},
+			RefreshRate: r.RefreshRate,
+			saved:       true,
+		})
+	}
+	sort.Slice(h.entries, func(i, j int) bool {
+		return h.entries[i].Seq < h.entries[j].Seq
+	})
+	h.prune()
+
+	return h, nil
+}
+
+// Add appends a frame, dropping the oldest once the history is full
+// The frame is kept even if it can't be written to disk; the error is returned
+func (h *History) Add(frame display.Frame, refreshRate int) (Entry, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	seq := 1
+	if n := len(h.entries); n > 0 {
+		seq = h.entries[n-1].Seq + 1
+	}
+	entry := &Entry{Seq: seq, Frame: frame, RefreshRate: refreshRate}
+	h.entries = append(h.entries, entry)
+
+	var err error
+	if h.dir != "" {
+		err = h.save(entry)
+		entry.saved = err == nil
+	}
+	h.prune()
+
+	return *entry, err
+}
+
+// Len returns the number of frames in the history
+func (h *History) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.entries)
+}
+
+// Latest returns the newest frame, if any
+func (h *History) Latest() (Entry, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.entries) == 0 {
+		return Entry{}, false
+	}
+	return h.load(len(h.entries) - 1)
+}
+
+// Oldest returns the oldest frame, if any
+func (h *History) Oldest() (Entry, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.entries) == 0 {
+		return Entry{}, false
+	}
+	return h.load(0)
+}
+
+// Recent returns up to limit of the newest frames received after since that can
+// still be read, oldest first; older frames are not read from disk
+func (h *History) Recent(since time.Time, limit int) []Entry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	start := len(h.entries)
+	for start > 0 && len(h.entries)-start < limit && h.entries[start-1].Frame.Time.After(since) {
+		start--
+	}
+	entries := make([]Entry, 0, len(h.entries)-start)
+	for i := start; i < len(h.entries); i++ {
+		if entry, ok := h.load(i); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Step returns the frame delta positions away from the frame with sequence
+// number seq (-1 for the previous frame, 1 for the next)
+// It returns false when there is no such frame
+func (h *History) Step(seq, delta int) (Entry, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	i := h.index(seq)
+	if i < 0 || i+delta < 0 || i+delta >= len(h.entries) {
+		return Entry{}, false
+	}
+	return h.load(i + delta)
+}
+
+// Position returns the 1-based position of a frame and the number of frames
+// The position is 0 if the frame is no longer in the history
+func (h *History) Position(seq int) (int, int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.index(seq) + 1, len(h.entries)
+}
+
+// index finds a frame by sequence number, returning -1 if it is not in the history
+// The caller must hold h.mu
+func (h *History) index(seq int) int {
+	i := sort.Search(len(h.entries), func(i int) bool {
+		return h.entries[i].Seq >= seq
+	})
+	if i < len(h.entries) && h.entries[i].Seq == seq {
+		return i
+	}
+	return -1
+}
+
+// load returns the entry at index i, reading its image from disk if it is not in memory
+// The caller must hold h.mu
+func (h *History) load(i int) (Entry, bool) {
+	entry := *h.entries[i]
+	if entry.Frame.Data == nil {
+		data, err := os.ReadFile(h.framePath(entry.Seq))
+		if err != nil {
+			return Entry{}, false
+		}
+		entry.Frame.Data = data
+	}
+	return entry, true
+}
+
+// prune drops the oldest frames beyond the size limit, and releases the images
+// of frames beyond MemoryFrames when they are on disk
+// The caller must hold h.mu
+func (h *History) prune() {
+	if excess := len(h.entries) - h.size; excess > 0 {
+		for _, entry := range h.entries[:excess] {
+			if entry.saved {
+				h.remove(entry.Seq)
+			}
+		}
+		h.entries = append([]*Entry(nil), h.entries[excess:]...)
+	}
+
+	for i := 0; i < len(h.entries)-MemoryFrames; i++ {
+		if h.entries[i].saved {
+			h.entries[i].Frame.Data = nil
+		}
+	}
+}
+
+// save writes a frame and its metadata to disk
+// The caller must hold h.mu
+func (h *History) save(entry *Entry) error {
+	if err := os.WriteFile(h.framePath(entry.Seq), entry.Frame.Data, 0600); err != nil {
+		return fmt.Errorf("failed to write history frame: %w", err)
+	}
+
+	data, err := json.Marshal(record{
+		Seq:         entry.Seq,
+		Filename:    entry.Frame.Filename,
+		FriendlyID:  entry.Frame.FriendlyID,
+		Time:        entry.Frame.Time,
+		RefreshRate: entry.RefreshRate,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode history metadata: %w", err)
+	}
+	// Metadata is written last, so a frame only counts once both files exist
+	if err := os.WriteFile(h.metadataPath(entry.Seq), data, 0600); err != nil {
+		return fmt.Errorf("failed to write history metadata: %w", err)
+	}
+	return nil
+}
+
+// remove deletes a frame from disk
+// The caller must hold h.mu
+func (h *History) remove(seq int) {
+	os.Remove(h.metadataPath(seq))
+	os.Remove(h.framePath(seq))
+}
+
+func (h *History) framePath(seq int) string {
+	return filepath.Join(h.dir, fmt.Sprintf("%08d.frame", seq))
+}
+
+func (h *History) metadataPath(seq int) string {
+	return filepath.Join(h.dir, fmt.Sprintf("%08d.json", seq))
+}
+
+// sanitize makes a profile or device name safe to use as a directory name
+func sanitize(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -34,6 +34,9 @@ type Logger struct {
 	mu         sync.Mutex
 	maxEntries int
 	verbose    bool
+	onFlush    func(err error)
+	name       string       // Device name shown in console output (multi-device mode)
+	httpClient *http.Client // Client used to upload logs
 }
 
 // NewLogger creates a new logger instance
@@ -44,9 +47,32 @@ func NewLogger(baseURL, apiKey string, verbose bool) *Logger {
 		entries:    make([]LogEntry, 0, 20),
 		maxEntries: 20, // Keep last 20 entries
 		verbose:    verbose,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
 	}
 }
 
+// SetTransport sets the HTTP transport used to upload logs
+// Loggers for several devices can share one transport with the API clients
+func (l *Logger) SetTransport(transport http.RoundTripper) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.httpClient.Transport = transport
+}
+
+// SetName sets the device name shown in console output
+func (l *Logger) SetName(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.name = name
+}
+
+// SetOnFlush sets a callback invoked with the outcome of every log upload attempt
+func (l *Logger) SetOnFlush(callback func(err error)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onFlush = callback
+}
+
 // Log adds a log entry
 func (l *Logger) Log(level LogLevel, message string, details any) {
 	l.mu.Lock()
@@ -136,13 +162,14 @@ func (l *Logger) Flush() error {
 	req.Header.Set("Access-Token", l.apiKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := l.httpClient.Do(req)
 	if err != nil {
 		if l.verbose {
 			fmt.Printf("[Logger] Failed to send logs: %v\n", err)
 		}
-		return fmt.Errorf("failed to send logs: %w", err)
+		err = fmt.Errorf("failed to send logs: %w", err)
+		l.notifyFlush(err)
+		return err
 	}
 	defer resp.Body.Close()
 
@@ -150,9 +177,13 @@ func (l *Logger) Flush() error {
 		if l.verbose {
 			fmt.Printf("[Logger] Unexpected response status: %d\n", resp.StatusCode)
 		}
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		err := fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		l.notifyFlush(err)
+		return err
 	}
 
+	l.notifyFlush(nil)
+
 	if l.verbose {
 		fmt.Printf("[Logger] ✓ Successfully sent %d log entries to API (status: %d)\n", len(l.entries), resp.StatusCode)
 	}
@@ -182,6 +213,13 @@ func (l *Logger) FlushOnError() error {
 	return nil
 }
 
+// notifyFlush reports a log upload outcome to the flush callback (caller holds l.mu)
+func (l *Logger) notifyFlush(err error) {
+	if l.onFlush != nil {
+		l.onFlush(err)
+	}
+}
+
 // printEntry prints a log entry to console
 func (l *Logger) printEntry(entry LogEntry) {
 	prefix := ""
@@ -193,6 +231,9 @@ func (l *Logger) printEntry(entry LogEntry) {
 	case LogLevelError:
 		prefix = "[ERROR]"
 	}
+	if l.name != "" {
+		prefix += " [" + l.name + "]"
+	}
 
 	if entry.Details != nil {
 		detailsJSON, _ := json.Marshal(entry.Details)
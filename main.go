@@ -1,6 +1,19 @@
 package main
 
+import "os"
+
 func main() {
+	// Subcommands are dispatched before the GUI flags are parsed
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(runConfigCommand(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "fetch" {
+		os.Exit(runFetchCommand(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "timelapse" {
+		os.Exit(runTimelapseCommand(os.Args[2:]))
+	}
+
 	// Run the GUI application
 	runGUIApp()
 }
@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+)
+
+// identityNamespace salts derived identities so the raw machine ID is never exposed
+// (as recommended by machine-id(5))
+const identityNamespace = "trmnl-go device identity"
+
+// DeriveMAC derives a stable, locally administered unicast MAC address from a seed
+// The same seed always produces the same address
+func DeriveMAC(seed string) string {
+	sum := sha256.Sum256([]byte(identityNamespace + "\x00" + seed))
+	// Set locally administered bit (bit 1 of first byte), clear multicast bit
+	sum[0] = (sum[0] | 0x02) & 0xFE
+	return fmt.Sprintf("%02X:%02X:%02X:%02X:%02X:%02X",
+		sum[0], sum[1], sum[2], sum[3], sum[4], sum[5])
+}
+
+// DeriveMACFromMachineID derives a stable MAC address from the host's machine ID
+func DeriveMACFromMachineID() (string, error) {
+	id, err := MachineID()
+	if err != nil {
+		return "", err
+	}
+	return DeriveMAC(id), nil
+}
+
+// normalizeMachineID trims whitespace and lower-cases a machine ID
+func normalizeMachineID(id string) (string, error) {
+	id = strings.ToLower(strings.TrimSpace(id))
+	if id == "" {
+		return "", fmt.Errorf("machine ID is empty")
+	}
+	return id, nil
+}
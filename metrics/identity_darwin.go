@@ -0,0 +1,29 @@
+//go:build darwin
+
+package metrics
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// MachineID returns the hardware platform UUID (IOPlatformUUID)
+func MachineID() (string, error) {
+	output, err := exec.Command("ioreg", "-rd1", "-c", "IOPlatformExpertDevice").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to query IOPlatformExpertDevice: %w", err)
+	}
+
+	// Line format: "IOPlatformUUID" = "XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX"
+	for _, line := range strings.Split(string(output), "\n") {
+		if !strings.Contains(line, "IOPlatformUUID") {
+			continue
+		}
+		if parts := strings.SplitN(line, "=", 2); len(parts) == 2 {
+			return normalizeMachineID(strings.Trim(strings.TrimSpace(parts[1]), "\""))
+		}
+	}
+
+	return "", fmt.Errorf("IOPlatformUUID not found")
+}
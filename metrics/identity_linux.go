@@ -0,0 +1,22 @@
+//go:build linux
+
+package metrics
+
+import (
+	"fmt"
+	"os"
+)
+
+// MachineID returns the systemd/D-Bus machine ID
+func MachineID() (string, error) {
+	for _, path := range []string{"/etc/machine-id", "/var/lib/dbus/machine-id"} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if id, err := normalizeMachineID(string(data)); err == nil {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("no machine ID found in /etc/machine-id or /var/lib/dbus/machine-id")
+}
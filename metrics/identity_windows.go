@@ -0,0 +1,27 @@
+//go:build windows
+
+package metrics
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// MachineID returns the Windows MachineGuid from the registry
+func MachineID() (string, error) {
+	output, err := exec.Command("reg", "query", `HKLM\SOFTWARE\Microsoft\Cryptography`, "/v", "MachineGuid").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to query MachineGuid: %w", err)
+	}
+
+	// Line format: MachineGuid    REG_SZ    xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 3 && fields[0] == "MachineGuid" {
+			return normalizeMachineID(fields[2])
+		}
+	}
+
+	return "", fmt.Errorf("MachineGuid not found")
+}
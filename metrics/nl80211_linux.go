@@ -0,0 +1,366 @@
+//go:build linux
+
+package metrics
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// Generic netlink and nl80211 constants (from linux/genetlink.h and linux/nl80211.h)
+const (
+	netlinkGeneric = 16 // NETLINK_GENERIC
+
+	genlIDCtrl            = 0x10 // GENL_ID_CTRL
+	ctrlCmdGetFamily      = 3    // CTRL_CMD_GETFAMILY
+	ctrlAttrFamilyID      = 1    // CTRL_ATTR_FAMILY_ID
+	ctrlAttrFamilyName    = 2    // CTRL_ATTR_FAMILY_NAME
+	nl80211FamilyName     = "nl80211"
+	nl80211CmdGetIface    = 5  // NL80211_CMD_GET_INTERFACE
+	nl80211CmdGetStation  = 17 // NL80211_CMD_GET_STATION
+	nl80211AttrIfindex    = 3  // NL80211_ATTR_IFINDEX
+	nl80211AttrIfname     = 4  // NL80211_ATTR_IFNAME
+	nl80211AttrStaInfo    = 21 // NL80211_ATTR_STA_INFO
+	nl80211AttrSSID       = 52 // NL80211_ATTR_SSID
+	nl80211StaInfoSignal  = 7  // NL80211_STA_INFO_SIGNAL
+	nl80211StaInfoTxRate  = 8  // NL80211_STA_INFO_TX_BITRATE
+	nl80211StaInfoSigAvg  = 13 // NL80211_STA_INFO_SIGNAL_AVG
+	nl80211RateBitrate    = 1  // NL80211_RATE_INFO_BITRATE (u16, 100 kbit/s)
+	nl80211RateBitrate32  = 5  // NL80211_RATE_INFO_BITRATE32 (u32, 100 kbit/s)
+	nlaTypeMask           = 0x3fff
+	nlmsgHeaderLen        = syscall.NLMSG_HDRLEN
+	genlHeaderLen         = 4
+	netlinkReceiveBufSize = 32 * 1024
+)
+
+// nlAttr is a decoded netlink attribute
+type nlAttr struct {
+	typ  uint16
+	data []byte
+}
+
+// wirelessInterface is an nl80211 interface
+type wirelessInterface struct {
+	index int
+	name  string
+	ssid  string
+}
+
+// nl80211Conn is a generic netlink socket bound to the nl80211 family
+type nl80211Conn struct {
+	fd     int
+	family uint16
+	seq    uint32
+}
+
+// dialNL80211 opens a generic netlink socket and resolves the nl80211 family
+func dialNL80211() (*nl80211Conn, error) {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, netlinkGeneric)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open netlink socket: %w", err)
+	}
+
+	if err := syscall.Bind(fd, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}); err != nil {
+		syscall.Close(fd)
+		return nil, fmt.Errorf("failed to bind netlink socket: %w", err)
+	}
+
+	// Don't let a misbehaving driver block the refresh loop
+	timeout := syscall.NsecToTimeval(int64(2e9))
+	syscall.SetsockoptTimeval(fd, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &timeout)
+
+	c := &nl80211Conn{fd: fd}
+
+	msgs, err := c.execute(genlIDCtrl, ctrlCmdGetFamily, 0, encodeAttrs(nlAttr{
+		typ:  ctrlAttrFamilyName,
+		data: append([]byte(nl80211FamilyName), 0),
+	}))
+	if err != nil {
+		c.Close()
+		return nil, fmt.Errorf("nl80211 family not available: %w", err)
+	}
+
+	for _, msg := range msgs {
+		for _, attr := range decodeAttrs(msg) {
+			if attr.typ == ctrlAttrFamilyID && len(attr.data) >= 2 {
+				c.family = binary.NativeEndian.Uint16(attr.data)
+			}
+		}
+	}
+	if c.family == 0 {
+		c.Close()
+		return nil, fmt.Errorf("nl80211 family ID not found")
+	}
+
+	return c, nil
+}
+
+// Close closes the netlink socket
+func (c *nl80211Conn) Close() error {
+	return syscall.Close(c.fd)
+}
+
+// interfaces lists all nl80211 interfaces
+func (c *nl80211Conn) interfaces() ([]wirelessInterface, error) {
+	msgs, err := c.execute(c.family, nl80211CmdGetIface, syscall.NLM_F_DUMP, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var ifaces []wirelessInterface
+	for _, msg := range msgs {
+		var iface wirelessInterface
+		for _, attr := range decodeAttrs(msg) {
+			switch attr.typ {
+			case nl80211AttrIfindex:
+				if len(attr.data) >= 4 {
+					iface.index = int(binary.NativeEndian.Uint32(attr.data))
+				}
+			case nl80211AttrIfname:
+				iface.name = nullTerminated(attr.data)
+			case nl80211AttrSSID:
+				iface.ssid = string(attr.data)
+			}
+		}
+		if iface.index > 0 {
+			ifaces = append(ifaces, iface)
+		}
+	}
+
+	return ifaces, nil
+}
+
+// station returns the signal (dBm) and transmit bitrate (Mbit/s) of the access point
+// the interface is associated with
+func (c *nl80211Conn) station(ifindex int) (int, float64, error) {
+	index := make([]byte, 4)
+	binary.NativeEndian.PutUint32(index, uint32(ifindex))
+
+	msgs, err := c.execute(c.family, nl80211CmdGetStation, syscall.NLM_F_DUMP,
+		encodeAttrs(nlAttr{typ: nl80211AttrIfindex, data: index}))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, msg := range msgs {
+		for _, attr := range decodeAttrs(msg) {
+			if attr.typ != nl80211AttrStaInfo {
+				continue
+			}
+
+			signal, signalAvg, bitrate := 0, 0, 0.0
+			for _, info := range decodeAttrs(attr.data) {
+				switch info.typ {
+				case nl80211StaInfoSignal:
+					if len(info.data) >= 1 {
+						signal = int(int8(info.data[0]))
+					}
+				case nl80211StaInfoSigAvg:
+					if len(info.data) >= 1 {
+						signalAvg = int(int8(info.data[0]))
+					}
+				case nl80211StaInfoTxRate:
+					bitrate = decodeBitrate(info.data)
+				}
+			}
+
+			if signal == 0 {
+				signal = signalAvg
+			}
+			if signal != 0 {
+				return signal, bitrate, nil
+			}
+		}
+	}
+
+	return 0, 0, fmt.Errorf("interface %d is not associated", ifindex)
+}
+
+// decodeBitrate reads a nested NL80211_STA_INFO_TX_BITRATE attribute in Mbit/s
+func decodeBitrate(data []byte) float64 {
+	var bitrate uint32
+	for _, rate := range decodeAttrs(data) {
+		switch rate.typ {
+		case nl80211RateBitrate32:
+			if len(rate.data) >= 4 {
+				bitrate = binary.NativeEndian.Uint32(rate.data)
+			}
+		case nl80211RateBitrate:
+			if len(rate.data) >= 2 && bitrate == 0 {
+				bitrate = uint32(binary.NativeEndian.Uint16(rate.data))
+			}
+		}
+	}
+	// Units of 100 kbit/s
+	return float64(bitrate) / 10
+}
+
+// execute sends a generic netlink request and collects the response payloads
+// (with the generic netlink header stripped)
+func (c *nl80211Conn) execute(family uint16, cmd uint8, flags uint16, attrs []byte) ([][]byte, error) {
+	c.seq++
+
+	length := nlmsgHeaderLen + genlHeaderLen + len(attrs)
+	req := make([]byte, length)
+	binary.NativeEndian.PutUint32(req[0:4], uint32(length))
+	binary.NativeEndian.PutUint16(req[4:6], family)
+	binary.NativeEndian.PutUint16(req[6:8], syscall.NLM_F_REQUEST|flags)
+	binary.NativeEndian.PutUint32(req[8:12], c.seq)
+	binary.NativeEndian.PutUint32(req[12:16], 0)
+	req[16] = cmd
+	req[17] = 1 // version
+	copy(req[nlmsgHeaderLen+genlHeaderLen:], attrs)
+
+	if err := syscall.Sendto(c.fd, req, 0, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}); err != nil {
+		return nil, fmt.Errorf("netlink send failed: %w", err)
+	}
+
+	var payloads [][]byte
+	buf := make([]byte, netlinkReceiveBufSize)
+	for {
+		n, _, err := syscall.Recvfrom(c.fd, buf, 0)
+		if err != nil {
+			return nil, fmt.Errorf("netlink receive failed: %w", err)
+		}
+
+		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			return nil, fmt.Errorf("invalid netlink message: %w", err)
+		}
+
+		for _, msg := range msgs {
+			if msg.Header.Seq != c.seq {
+				continue
+			}
+
+			switch msg.Header.Type {
+			case syscall.NLMSG_DONE:
+				return payloads, nil
+			case syscall.NLMSG_ERROR:
+				if len(msg.Data) >= 4 {
+					if errno := int32(binary.NativeEndian.Uint32(msg.Data)); errno != 0 {
+						return nil, os.NewSyscallError("netlink", syscall.Errno(-errno))
+					}
+				}
+				return payloads, nil
+			}
+
+			if len(msg.Data) >= genlHeaderLen {
+				// Copy out of the receive buffer, which is reused for the next read
+				payloads = append(payloads, append([]byte(nil), msg.Data[genlHeaderLen:]...))
+			}
+
+			// Non-dump requests are answered with a single message
+			if msg.Header.Flags&syscall.NLM_F_MULTI == 0 {
+				return payloads, nil
+			}
+		}
+	}
+}
+
+// decodeAttrs decodes a sequence of netlink attributes
+func decodeAttrs(data []byte) []nlAttr {
+	var attrs []nlAttr
+	for len(data) >= syscall.NLA_HDRLEN {
+		length := int(binary.NativeEndian.Uint16(data[0:2]))
+		typ := binary.NativeEndian.Uint16(data[2:4]) & nlaTypeMask
+		if length < syscall.NLA_HDRLEN || length > len(data) {
+			break
+		}
+
+		attrs = append(attrs, nlAttr{typ: typ, data: data[syscall.NLA_HDRLEN:length]})
+
+		aligned := nlaAlign(length)
+		if aligned > len(data) {
+			break
+		}
+		data = data[aligned:]
+	}
+	return attrs
+}
+
+// encodeAttrs encodes attributes with 4-byte alignment
+func encodeAttrs(attrs ...nlAttr) []byte {
+	var buf []byte
+	for _, attr := range attrs {
+		length := syscall.NLA_HDRLEN + len(attr.data)
+		encoded := make([]byte, nlaAlign(length))
+		binary.NativeEndian.PutUint16(encoded[0:2], uint16(length))
+		binary.NativeEndian.PutUint16(encoded[2:4], attr.typ)
+		copy(encoded[syscall.NLA_HDRLEN:], attr.data)
+		buf = append(buf, encoded...)
+	}
+	return buf
+}
+
+func nlaAlign(length int) int {
+	return (length + syscall.NLA_ALIGNTO - 1) & ^(syscall.NLA_ALIGNTO - 1)
+}
+
+// nullTerminated converts a NUL-terminated byte slice to a string
+func nullTerminated(data []byte) string {
+	for i, b := range data {
+		if b == 0 {
+			return string(data[:i])
+		}
+	}
+	return string(data)
+}
+
+// readNL80211 reads the wireless link for ifaceName via nl80211
+// Without an interface name, the default route interface is preferred,
+// then the first associated wireless interface
+func readNL80211(ifaceName string) (WiFiInfo, error) {
+	conn, err := dialNL80211()
+	if err != nil {
+		return WiFiInfo{}, err
+	}
+	defer conn.Close()
+
+	ifaces, err := conn.interfaces()
+	if err != nil {
+		return WiFiInfo{}, err
+	}
+
+	preferred := ifaceName
+	if preferred == "" {
+		preferred = defaultRouteInterface()
+	}
+
+	// Order candidates: the preferred interface first, then the rest (unless explicitly named)
+	var candidates []wirelessInterface
+	for _, iface := range ifaces {
+		if iface.name == preferred {
+			candidates = append([]wirelessInterface{iface}, candidates...)
+		} else if ifaceName == "" {
+			candidates = append(candidates, iface)
+		}
+	}
+	if len(candidates) == 0 {
+		if ifaceName != "" {
+			return WiFiInfo{}, fmt.Errorf("%s is not a wireless interface", ifaceName)
+		}
+		return WiFiInfo{}, fmt.Errorf("no wireless interfaces found")
+	}
+
+	var lastErr error
+	for _, iface := range candidates {
+		signal, bitrate, err := conn.station(iface.index)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return WiFiInfo{
+			Interface:   iface.name,
+			SSID:        iface.ssid,
+			RSSI:        signal,
+			Quality:     qualityFromRSSI(signal),
+			BitrateMbps: bitrate,
+			Source:      "nl80211",
+		}, nil
+	}
+
+	return WiFiInfo{}, lastErr
+}
@@ -0,0 +1,183 @@
+//go:build linux
+
+package metrics
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// powerSupplyRoot is where the kernel exposes power supplies
+const powerSupplyRoot = "/sys/class/power_supply"
+
+// powerSupply holds the sysfs attributes of a single battery
+// Energy values are in µWh, charge values in µAh, voltage in µV; -1 means missing
+type powerSupply struct {
+	name       string
+	capacity   float64
+	energyNow  float64
+	energyFull float64
+	chargeNow  float64
+	chargeFull float64
+	voltageNow float64
+	status     ChargingState
+}
+
+// readPowerSupplies enumerates all system batteries under root
+// Supplies that are not of type Battery, are not present, or power a peripheral
+// (scope Device, e.g. a wireless mouse) are skipped
+func readPowerSupplies(root string) []powerSupply {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+
+	var supplies []powerSupply
+	for _, entry := range entries {
+		dir := filepath.Join(root, entry.Name())
+
+		if readSysfsString(filepath.Join(dir, "type")) != "Battery" {
+			continue
+		}
+		if readSysfsString(filepath.Join(dir, "scope")) == "Device" {
+			continue
+		}
+		if present := readSysfsString(filepath.Join(dir, "present")); present == "0" {
+			continue
+		}
+
+		supplies = append(supplies, powerSupply{
+			name:       entry.Name(),
+			capacity:   readSysfsValue(filepath.Join(dir, "capacity")),
+			energyNow:  readSysfsValue(filepath.Join(dir, "energy_now")),
+			energyFull: readSysfsValue(filepath.Join(dir, "energy_full")),
+			chargeNow:  readSysfsValue(filepath.Join(dir, "charge_now")),
+			chargeFull: readSysfsValue(filepath.Join(dir, "charge_full")),
+			voltageNow: readSysfsValue(filepath.Join(dir, "voltage_now")),
+			status:     parseChargingStatus(readSysfsString(filepath.Join(dir, "status"))),
+		})
+	}
+
+	return supplies
+}
+
+// percent returns the battery percentage, computed from energy or charge when
+// the capacity attribute is missing, or -1 if it cannot be determined
+func (p powerSupply) percent() float64 {
+	switch {
+	case p.capacity >= 0:
+		return p.capacity
+	case p.energyNow >= 0 && p.energyFull > 0:
+		return clampPercent(p.energyNow / p.energyFull * 100)
+	case p.chargeNow >= 0 && p.chargeFull > 0:
+		return clampPercent(p.chargeNow / p.chargeFull * 100)
+	}
+	return -1
+}
+
+// readBatteryStatus aggregates all batteries under root
+// Multiple batteries are weighted by their full energy (or charge) when every
+// battery reports it, otherwise their percentages are averaged
+func readBatteryStatus(root string) (batteryStatus, bool) {
+	var known []powerSupply
+	for _, supply := range readPowerSupplies(root) {
+		if supply.percent() >= 0 {
+			known = append(known, supply)
+		}
+	}
+	if len(known) == 0 {
+		return batteryStatus{}, false
+	}
+
+	status := batteryStatus{
+		Percent:  weightedPercent(known),
+		Charging: combineChargingStates(known),
+	}
+
+	voltageSum, voltageCount := 0.0, 0
+	for _, supply := range known {
+		if supply.voltageNow > 0 {
+			voltageSum += supply.voltageNow / 1e6
+			voltageCount++
+		}
+	}
+	if voltageCount > 0 {
+		status.Voltage = voltageSum / float64(voltageCount)
+	}
+
+	return status, true
+}
+
+// weightedPercent combines per-battery percentages
+func weightedPercent(supplies []powerSupply) float64 {
+	weight := func(p powerSupply) float64 { return p.energyFull }
+	for _, supply := range supplies {
+		if supply.energyFull <= 0 {
+			weight = func(p powerSupply) float64 { return p.chargeFull }
+			break
+		}
+	}
+	for _, supply := range supplies {
+		if weight(supply) <= 0 {
+			weight = func(powerSupply) float64 { return 1 }
+			break
+		}
+	}
+
+	total, weights := 0.0, 0.0
+	for _, supply := range supplies {
+		total += supply.percent() * weight(supply)
+		weights += weight(supply)
+	}
+	return total / weights
+}
+
+// combineChargingStates merges the states of several batteries:
+// any charging battery means charging, otherwise any discharging battery means discharging
+func combineChargingStates(supplies []powerSupply) ChargingState {
+	seen := map[ChargingState]bool{}
+	for _, supply := range supplies {
+		seen[supply.status] = true
+	}
+
+	switch {
+	case seen[ChargingCharging]:
+		return ChargingCharging
+	case seen[ChargingDischarging]:
+		return ChargingDischarging
+	case seen[ChargingNotCharging]:
+		return ChargingNotCharging
+	case seen[ChargingFull] && len(seen) == 1:
+		return ChargingFull
+	}
+	return ChargingUnknown
+}
+
+// readSysfsString reads a sysfs attribute as a trimmed string ("" if missing)
+func readSysfsString(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// readSysfsValue reads a numeric sysfs attribute, returning -1 if missing or invalid
+func readSysfsValue(path string) float64 {
+	value, err := readSysfsFloat(path)
+	if err != nil {
+		return -1
+	}
+	return value
+}
+
+func clampPercent(percent float64) float64 {
+	if percent < 0 {
+		return 0
+	}
+	if percent > 100 {
+		return 100
+	}
+	return percent
+}
@@ -0,0 +1,119 @@
+//go:build linux
+
+package metrics
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeSupply creates a fake power supply directory with the given sysfs attributes
+func writeSupply(t *testing.T, root, name string, attrs map[string]string) {
+	t.Helper()
+	dir := filepath.Join(root, name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for attr, value := range attrs {
+		if err := os.WriteFile(filepath.Join(dir, attr), []byte(value+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReadBatteryStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		supplies map[string]map[string]string
+		ok       bool
+		percent  float64
+		voltage  float64
+		charging ChargingState
+	}{
+		{
+			name: "energy without capacity",
+			supplies: map[string]map[string]string{
+				"BAT0": {"type": "Battery", "energy_now": "30000000", "energy_full": "40000000", "voltage_now": "12000000", "status": "Discharging"},
+			},
+			ok:       true,
+			percent:  75,
+			voltage:  12,
+			charging: ChargingDischarging,
+		},
+		{
+			name: "charge without capacity",
+			supplies: map[string]map[string]string{
+				"BAT0": {"type": "Battery", "charge_now": "1000000", "charge_full": "4000000", "status": "Charging"},
+			},
+			ok:       true,
+			percent:  25,
+			charging: ChargingCharging,
+		},
+		{
+			name: "several batteries weighted by full energy",
+			supplies: map[string]map[string]string{
+				"BAT0": {"type": "Battery", "capacity": "100", "energy_now": "20000000", "energy_full": "20000000", "voltage_now": "12000000", "status": "Full"},
+				"BAT1": {"type": "Battery", "capacity": "50", "energy_now": "30000000", "energy_full": "60000000", "voltage_now": "11000000", "status": "Discharging"},
+			},
+			ok:       true,
+			percent:  62.5,
+			voltage:  11.5,
+			charging: ChargingDischarging,
+		},
+		{
+			name: "mains, USB and peripheral supplies are ignored",
+			supplies: map[string]map[string]string{
+				"AC":       {"type": "Mains", "online": "1"},
+				"usb":      {"type": "USB", "online": "1"},
+				"hidpp_0":  {"type": "Battery", "scope": "Device", "capacity": "5", "status": "Discharging"},
+				"BAT0":     {"type": "Battery", "capacity": "80", "status": "Not charging"},
+				"BAT1":     {"type": "Battery", "present": "0", "capacity": "0"},
+				"ucsi-src": {"type": "USB", "capacity": "10"},
+			},
+			ok:       true,
+			percent:  80,
+			charging: ChargingNotCharging,
+		},
+		{
+			name: "no batteries",
+			supplies: map[string]map[string]string{
+				"AC": {"type": "Mains", "online": "1"},
+			},
+			ok: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for name, attrs := range tt.supplies {
+				writeSupply(t, root, name, attrs)
+			}
+
+			status, ok := readBatteryStatus(root)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if math.Abs(status.Percent-tt.percent) > 0.01 {
+				t.Errorf("Percent = %.2f, want %.2f", status.Percent, tt.percent)
+			}
+			if math.Abs(status.Voltage-tt.voltage) > 0.01 {
+				t.Errorf("Voltage = %.2f, want %.2f", status.Voltage, tt.voltage)
+			}
+			if status.Charging != tt.charging {
+				t.Errorf("Charging = %q, want %q", status.Charging, tt.charging)
+			}
+		})
+	}
+}
+
+func TestReadBatteryStatusMissingRoot(t *testing.T) {
+	if _, ok := readBatteryStatus(filepath.Join(t.TempDir(), "missing")); ok {
+		t.Error("ok = true for a missing power_supply directory")
+	}
+}
@@ -0,0 +1,193 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Metrics provider kinds (selectable via config)
+const (
+	ProviderHost      = "host"
+	ProviderStatic    = "static"
+	ProviderSimulated = "simulated"
+	ProviderCommand   = "command"
+)
+
+// DefaultCommandTimeout limits how long a metrics command may run
+const DefaultCommandTimeout = 10 * time.Second
+
+// ChargingState describes whether the battery is charging
+type ChargingState string
+
+const (
+	ChargingUnknown     ChargingState = ""
+	ChargingCharging    ChargingState = "charging"
+	ChargingDischarging ChargingState = "discharging"
+	ChargingFull        ChargingState = "full"
+	ChargingNotCharging ChargingState = "not_charging"
+)
+
+// Reading is a snapshot of device metrics from a Provider
+// Unavailable values are left at zero, except BatteryPercent which is -1
+type Reading struct {
+	BatteryPercent float64       // Battery percentage (0-100), -1 if unavailable
+	BatteryVoltage float64       // Measured battery voltage (V), 0 if unavailable
+	Charging       ChargingState // Charging state, empty if unavailable
+	RSSI           int           // WiFi signal strength (dBm), 0 if unavailable
+	Temperature    float64       // Device temperature (°C), 0 if unavailable
+	Uptime         time.Duration // System uptime, 0 if unavailable
+}
+
+// Provider supplies battery, WiFi and system readings
+type Provider interface {
+	Read() (Reading, error)
+}
+
+// Metrics converts a reading to the SystemMetrics reported to the server,
+// substituting defaults for unavailable values
+func (r Reading) Metrics() SystemMetrics {
+	metrics := SystemMetrics{
+		BatteryVoltage: 100.0, // Default for desktops without battery
+		RSSI:           -50,   // Default decent signal
+	}
+
+	if r.BatteryPercent >= 0 {
+		metrics.BatteryVoltage = r.BatteryPercent
+	}
+
+	metrics.MeasuredVoltage = r.BatteryVoltage
+	metrics.Charging = r.Charging
+
+	if r.RSSI != 0 {
+		metrics.RSSI = r.RSSI
+	}
+
+	return metrics
+}
+
+// CollectFrom reads metrics from a provider, falling back to defaults on error
+func CollectFrom(p Provider) SystemMetrics {
+	reading, err := p.Read()
+	if err != nil {
+		return Reading{BatteryPercent: -1}.Metrics()
+	}
+	return reading.Metrics()
+}
+
+// HostProvider reads metrics from the host operating system
+type HostProvider struct {
+	// Interface selects the wireless interface for RSSI (empty selects automatically)
+	Interface string
+}
+
+// Read gathers current host metrics
+func (p HostProvider) Read() (Reading, error) {
+	battery := getBatteryStatus()
+	return Reading{
+		BatteryPercent: battery.Percent,
+		BatteryVoltage: battery.Voltage,
+		Charging:       battery.Charging,
+		RSSI:           getWiFiSignal(p.Interface),
+		Temperature:    getTemperature(),
+		Uptime:         getUptime(),
+	}, nil
+}
+
+// ReadingSpec is the JSON form of a Reading, used by config files and metrics commands
+// Omitted fields are reported as unavailable
+type ReadingSpec struct {
+	BatteryPercent *float64 `json:"battery_percent,omitempty"`
+	BatteryVoltage *float64 `json:"battery_voltage,omitempty"`
+	Charging       string   `json:"charging,omitempty"`
+	RSSI           *int     `json:"rssi,omitempty"`
+	Temperature    *float64 `json:"temperature,omitempty"`
+	UptimeSeconds  *float64 `json:"uptime_seconds,omitempty"`
+}
+
+// Reading converts the spec to a Reading
+func (s ReadingSpec) Reading() Reading {
+	r := Reading{
+		BatteryPercent: -1,
+		Charging:       ChargingState(s.Charging),
+	}
+	if s.BatteryPercent != nil {
+		r.BatteryPercent = *s.BatteryPercent
+	}
+	if s.BatteryVoltage != nil {
+		r.BatteryVoltage = *s.BatteryVoltage
+	}
+	if s.RSSI != nil {
+		r.RSSI = *s.RSSI
+	}
+	if s.Temperature != nil {
+		r.Temperature = *s.Temperature
+	}
+	if s.UptimeSeconds != nil {
+		r.Uptime = time.Duration(*s.UptimeSeconds * float64(time.Second))
+	}
+	return r
+}
+
+// StaticProvider always returns the same reading
+type StaticProvider struct {
+	reading Reading
+}
+
+// NewStaticProvider creates a provider returning fixed values
+func NewStaticProvider(spec ReadingSpec) *StaticProvider {
+	return &StaticProvider{reading: spec.Reading()}
+}
+
+// Read returns the fixed reading
+func (p *StaticProvider) Read() (Reading, error) {
+	return p.reading, nil
+}
+
+// CommandProvider runs an external command and parses its JSON output as a ReadingSpec
+// Example output: {"battery_percent": 42, "charging": "discharging", "rssi": -67}
+type CommandProvider struct {
+	command string
+	args    []string
+	timeout time.Duration
+}
+
+// NewCommandProvider creates a provider from a command line (split on whitespace)
+func NewCommandProvider(commandLine string) (*CommandProvider, error) {
+	fields := strings.Fields(commandLine)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("metrics command cannot be empty")
+	}
+
+	return &CommandProvider{
+		command: fields[0],
+		args:    fields[1:],
+		timeout: DefaultCommandTimeout,
+	}, nil
+}
+
+// Read runs the command and parses its output
+func (p *CommandProvider) Read() (Reading, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.command, p.args...)
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return Reading{}, fmt.Errorf("metrics command failed: %w (%s)", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var spec ReadingSpec
+	if err := json.Unmarshal(output, &spec); err != nil {
+		return Reading{}, fmt.Errorf("failed to parse metrics command output: %w", err)
+	}
+
+	return spec.Reading(), nil
+}
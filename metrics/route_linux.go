@@ -0,0 +1,171 @@
+//go:build linux
+
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Routing tables exposed by the kernel
+const (
+	procNetRoute     = "/proc/net/route"
+	procNetIPv6Route = "/proc/net/ipv6_route"
+)
+
+// routeFlagUp marks a usable route (RTF_UP)
+const routeFlagUp = 0x1
+
+// virtualInterfacePrefixes identifies container and VM bridges whose MAC
+// addresses change between reboots and must not be used as device identity
+var virtualInterfacePrefixes = []string{"docker", "veth", "br-", "virbr"}
+
+// isVirtualInterface reports whether name is a container or VM bridge interface
+func isVirtualInterface(name string) bool {
+	for _, prefix := range virtualInterfacePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultRoute is a default route candidate
+type defaultRoute struct {
+	iface  string
+	metric int
+}
+
+// defaultRouteInterface returns the interface of the default route with the
+// lowest metric (IPv4 preferred over IPv6), or "" if there is none
+// Loopback and virtual interfaces are ignored
+func defaultRouteInterface() string {
+	for _, routes := range [][]defaultRoute{readIPv4DefaultRoutes(), readIPv6DefaultRoutes()} {
+		best := defaultRoute{metric: -1}
+		for _, route := range routes {
+			if route.iface == "lo" || isVirtualInterface(route.iface) {
+				continue
+			}
+			if best.metric < 0 || route.metric < best.metric {
+				best = route
+			}
+		}
+		if best.iface != "" {
+			return best.iface
+		}
+	}
+	return ""
+}
+
+// readIPv4DefaultRoutes parses default routes from /proc/net/route
+func readIPv4DefaultRoutes() []defaultRoute {
+	file, err := os.Open(procNetRoute)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var routes []defaultRoute
+	scanner := bufio.NewScanner(file)
+
+	// Skip the header line
+	// Format: Iface Destination Gateway Flags RefCnt Use Metric Mask ...
+	scanner.Scan()
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 8 {
+			continue
+		}
+
+		// Default route: destination and mask 00000000
+		if fields[1] != "00000000" || fields[7] != "00000000" {
+			continue
+		}
+
+		flags, err := strconv.ParseUint(fields[3], 16, 32)
+		if err != nil || flags&routeFlagUp == 0 {
+			continue
+		}
+
+		metric, err := strconv.Atoi(fields[6])
+		if err != nil {
+			continue
+		}
+
+		routes = append(routes, defaultRoute{iface: fields[0], metric: metric})
+	}
+
+	return routes
+}
+
+// readIPv6DefaultRoutes parses default routes from /proc/net/ipv6_route
+func readIPv6DefaultRoutes() []defaultRoute {
+	file, err := os.Open(procNetIPv6Route)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var routes []defaultRoute
+	scanner := bufio.NewScanner(file)
+
+	// Format (no header): dest dest_prefix src src_prefix next_hop metric refcnt use flags iface
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+
+		// Default route: ::/0
+		if strings.Trim(fields[0], "0") != "" || fields[1] != "00" {
+			continue
+		}
+
+		flags, err := strconv.ParseUint(fields[8], 16, 32)
+		if err != nil || flags&routeFlagUp == 0 {
+			continue
+		}
+
+		metric, err := strconv.ParseUint(fields[5], 16, 32)
+		if err != nil {
+			continue
+		}
+
+		routes = append(routes, defaultRoute{iface: fields[9], metric: int(metric)})
+	}
+
+	return routes
+}
+
+// primaryInterface returns the interface used for device identity: the default
+// route interface, falling back to the first up, non-loopback, non-virtual
+// interface with a MAC address
+func primaryInterface() (*net.Interface, error) {
+	if name := defaultRouteInterface(); name != "" {
+		if iface, err := net.InterfaceByName(name); err == nil && len(iface.HardwareAddr) > 0 {
+			return iface, nil
+		}
+	}
+
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range interfaces {
+		iface := &interfaces[i]
+		if iface.Flags&net.FlagLoopback != 0 || iface.Flags&net.FlagUp == 0 {
+			continue
+		}
+		if isVirtualInterface(iface.Name) || len(iface.HardwareAddr) == 0 {
+			continue
+		}
+		return iface, nil
+	}
+
+	return nil, fmt.Errorf("no network interface found")
+}
@@ -0,0 +1,274 @@
+package metrics
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Battery simulation profiles
+const (
+	BatteryProfileFixed     = "fixed"     // Constant percentage
+	BatteryProfileLinear    = "linear"    // Constant drain per refresh
+	BatteryProfileRealistic = "realistic" // Li-ion style drain: fast at the ends, slow plateau
+	BatteryProfileCharging  = "charging"  // Discharge to a floor, then charge back to full, repeatedly
+)
+
+// WiFi simulation profiles
+const (
+	RSSIProfileFixed   = "fixed"   // Constant signal strength
+	RSSIProfileDrift   = "drift"   // Random walk within a range
+	RSSIProfileDropout = "dropout" // Drift with periodic signal loss
+)
+
+// Simulation defaults
+const (
+	DefaultSimBatteryStart     = 100.0
+	DefaultSimDrainPerRefresh  = 0.5
+	DefaultSimChargePerRefresh = 2.0
+	DefaultSimRSSI             = -50
+	DefaultSimRSSIDrift        = 3
+	DefaultSimDropoutEvery     = 10
+	DefaultSimDropoutLength    = 2
+	SimRSSIMin                 = -90
+	SimRSSIMax                 = -30
+	DropoutRSSI                = -100 // Reported while the simulated signal is lost
+)
+
+// SimulationProfile describes how simulated battery and WiFi values evolve
+// Values advance once per refresh (each call to Simulator.Read)
+type SimulationProfile struct {
+	// Battery profile: fixed, linear, realistic or charging (empty uses host readings)
+	Battery string `json:"battery,omitempty"`
+
+	// BatteryStart is the starting (or fixed) battery percentage (default 100)
+	// A pointer, so that 0 (an empty battery) can be told apart from unset
+	BatteryStart *float64 `json:"battery_start,omitempty"`
+
+	// BatteryMin is the floor where discharge stops, or charging starts (default 0)
+	BatteryMin float64 `json:"battery_min,omitempty"`
+
+	// DrainPerRefresh is the percentage lost per refresh (default 0.5)
+	DrainPerRefresh float64 `json:"drain_per_refresh,omitempty"`
+
+	// ChargePerRefresh is the percentage gained per refresh while charging (default 2)
+	ChargePerRefresh float64 `json:"charge_per_refresh,omitempty"`
+
+	// RSSI profile: fixed, drift or dropout (empty uses host readings)
+	RSSI string `json:"rssi,omitempty"`
+
+	// RSSIBase is the starting (or fixed) signal strength in dBm (default -50)
+	RSSIBase int `json:"rssi_base,omitempty"`
+
+	// RSSIDrift is the maximum change in dBm per refresh (default 3)
+	RSSIDrift int `json:"rssi_drift,omitempty"`
+
+	// DropoutEvery is the number of refreshes between signal dropouts (default 10)
+	DropoutEvery int `json:"dropout_every,omitempty"`
+
+	// DropoutLength is how many refreshes a dropout lasts (default 2)
+	DropoutLength int `json:"dropout_length,omitempty"`
+
+	// Seed makes drift reproducible (0 uses the current time)
+	Seed int64 `json:"seed,omitempty"`
+}
+
+// Validate checks that the profile names are known
+func (p SimulationProfile) Validate() error {
+	switch p.Battery {
+	case "", BatteryProfileFixed, BatteryProfileLinear, BatteryProfileRealistic, BatteryProfileCharging:
+	default:
+		return fmt.Errorf("unknown battery simulation profile: %s", p.Battery)
+	}
+
+	switch p.RSSI {
+	case "", RSSIProfileFixed, RSSIProfileDrift, RSSIProfileDropout:
+	default:
+		return fmt.Errorf("unknown RSSI simulation profile: %s", p.RSSI)
+	}
+
+	if p.BatteryStart != nil && (*p.BatteryStart < 0 || *p.BatteryStart > 100) {
+		return fmt.Errorf("simulated battery percentages must be between 0 and 100")
+	}
+	if p.BatteryMin < 0 || p.BatteryMin > 100 {
+		return fmt.Errorf("simulated battery percentages must be between 0 and 100")
+	}
+
+	return nil
+}
+
+// Simulator produces scripted battery and WiFi readings
+// It implements Provider; dimensions without a profile come from the fallback provider
+type Simulator struct {
+	mu        sync.Mutex
+	profile   SimulationProfile
+	fallback  Provider
+	refreshes int
+	battery   float64
+	charging  bool
+	rssi      int
+	rng       *rand.Rand
+}
+
+// NewSimulator creates a simulator for the given profile, filling in defaults
+// Values that are not simulated are read from the host
+func NewSimulator(profile SimulationProfile) (*Simulator, error) {
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+
+	batteryStart := DefaultSimBatteryStart
+	if profile.BatteryStart != nil {
+		batteryStart = *profile.BatteryStart
+	}
+	if profile.DrainPerRefresh <= 0 {
+		profile.DrainPerRefresh = DefaultSimDrainPerRefresh
+	}
+	if profile.ChargePerRefresh <= 0 {
+		profile.ChargePerRefresh = DefaultSimChargePerRefresh
+	}
+	if profile.RSSIBase == 0 {
+		profile.RSSIBase = DefaultSimRSSI
+	}
+	if profile.RSSIDrift <= 0 {
+		profile.RSSIDrift = DefaultSimRSSIDrift
+	}
+	if profile.DropoutEvery <= 0 {
+		profile.DropoutEvery = DefaultSimDropoutEvery
+	}
+	if profile.DropoutLength <= 0 {
+		profile.DropoutLength = DefaultSimDropoutLength
+	}
+
+	seed := profile.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	return &Simulator{
+		profile:  profile,
+		fallback: HostProvider{},
+		battery:  batteryStart,
+		rssi:     profile.RSSIBase,
+		rng:      rand.New(rand.NewSource(seed)),
+	}, nil
+}
+
+// SetFallback sets the provider used for values that are not simulated
+func (s *Simulator) SetFallback(p Provider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fallback = p
+}
+
+// Current returns the simulated reading without advancing the simulation
+func (s *Simulator) Current() (Reading, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current()
+}
+
+// Read returns the simulated reading for this refresh and advances the simulation
+func (s *Simulator) Read() (Reading, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, err := s.current()
+	s.advance()
+	return r, err
+}
+
+// Refreshes returns how many refreshes have been simulated
+func (s *Simulator) Refreshes() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.refreshes
+}
+
+func (s *Simulator) current() (Reading, error) {
+	r, err := s.fallback.Read()
+	if err != nil {
+		r = Reading{BatteryPercent: -1}
+	}
+
+	if s.profile.Battery != "" {
+		r.BatteryPercent = s.battery
+		// Measured voltage would contradict the simulated percentage
+		r.BatteryVoltage = 0
+		r.Charging = ChargingDischarging
+		if s.charging {
+			r.Charging = ChargingCharging
+		}
+	}
+
+	if s.profile.RSSI != "" {
+		r.RSSI = s.rssi
+		if s.inDropout() {
+			r.RSSI = DropoutRSSI
+		}
+	}
+
+	return r, nil
+}
+
+// inDropout reports whether the current refresh falls inside a simulated dropout
+func (s *Simulator) inDropout() bool {
+	if s.profile.RSSI != RSSIProfileDropout || s.refreshes == 0 {
+		return false
+	}
+	return s.refreshes%s.profile.DropoutEvery < s.profile.DropoutLength
+}
+
+func (s *Simulator) advance() {
+	s.refreshes++
+
+	switch s.profile.Battery {
+	case BatteryProfileLinear:
+		s.battery -= s.profile.DrainPerRefresh
+
+	case BatteryProfileRealistic:
+		// Li-ion cells drop quickly when nearly full and nearly empty,
+		// and hold a long plateau in between
+		rate := s.profile.DrainPerRefresh
+		switch {
+		case s.battery > 90:
+			rate *= 1.5
+		case s.battery < 15:
+			rate *= 2
+		default:
+			rate *= 0.8
+		}
+		s.battery -= rate
+
+	case BatteryProfileCharging:
+		if s.charging {
+			s.battery += s.profile.ChargePerRefresh
+			if s.battery >= 100 {
+				s.battery = 100
+				s.charging = false
+			}
+		} else {
+			s.battery -= s.profile.DrainPerRefresh
+			if s.battery <= s.profile.BatteryMin {
+				s.charging = true
+			}
+		}
+	}
+
+	if s.battery < s.profile.BatteryMin {
+		s.battery = s.profile.BatteryMin
+	}
+
+	switch s.profile.RSSI {
+	case RSSIProfileDrift, RSSIProfileDropout:
+		step := s.rng.Intn(2*s.profile.RSSIDrift+1) - s.profile.RSSIDrift
+		s.rssi += step
+		if s.rssi < SimRSSIMin {
+			s.rssi = SimRSSIMin
+		}
+		if s.rssi > SimRSSIMax {
+			s.rssi = SimRSSIMax
+		}
+	}
+}
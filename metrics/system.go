@@ -6,31 +6,32 @@ import (
 
 // SystemMetrics holds system information
 type SystemMetrics struct {
-	BatteryVoltage float64 // Battery percentage (0-100) or voltage equivalent
-	RSSI           int     // WiFi signal strength (dBm, typically -30 to -90)
+	BatteryVoltage  float64       // Battery percentage (0-100) or voltage equivalent
+	MeasuredVoltage float64       // Battery voltage measured by the host (V), 0 if unavailable
+	Charging        ChargingState // Battery charging state, empty if unavailable
+	RSSI            int           // WiFi signal strength (dBm, typically -30 to -90)
 }
 
-// Collect gathers current system metrics
-func Collect() SystemMetrics {
-	metrics := SystemMetrics{
-		BatteryVoltage: 100.0, // Default for desktops without battery
-		RSSI:           -50,   // Default decent signal
-	}
-
-	// Try to get actual battery percentage
-	if battery := getBatteryPercentage(); battery >= 0 {
-		metrics.BatteryVoltage = battery
-	}
-
-	// Try to get actual WiFi signal strength
-	if rssi := getWiFiSignal(); rssi != 0 {
-		metrics.RSSI = rssi
-	}
+// batteryStatus is the aggregate state of all system batteries
+type batteryStatus struct {
+	Percent  float64       // 0-100, or -1 if unavailable
+	Voltage  float64       // Average measured voltage (V), 0 if unavailable
+	Charging ChargingState // Combined charging state
+}
 
-	return metrics
+// Collect gathers current system metrics from the host
+func Collect() SystemMetrics {
+	return CollectFrom(HostProvider{})
 }
 
 // String returns a human-readable representation of the metrics
 func (m SystemMetrics) String() string {
-	return fmt.Sprintf("Battery: %.1f%%, WiFi: %d dBm", m.BatteryVoltage, m.RSSI)
+	battery := fmt.Sprintf("%.1f%%", m.BatteryVoltage)
+	if m.MeasuredVoltage > 0 {
+		battery += fmt.Sprintf(" (%.2fV measured)", m.MeasuredVoltage)
+	}
+	if m.Charging != ChargingUnknown {
+		battery += fmt.Sprintf(" %s", m.Charging)
+	}
+	return fmt.Sprintf("Battery: %s, WiFi: %d dBm", battery, m.RSSI)
 }
@@ -8,22 +8,152 @@ package metrics
 #import <CoreWLAN/CoreWLAN.h>
 #import <IOKit/ps/IOPowerSources.h>
 #import <IOKit/ps/IOPSKeys.h>
+#include <sys/sysctl.h>
+#include <sys/time.h>
+#include <stdlib.h>
+#include <string.h>
 
-int getWiFiRSSI() {
+// Returns the named WiFi interface, or the default one if name is NULL or empty
+CWInterface* getWiFiInterface(const char* name) {
+	CWWiFiClient *client = [CWWiFiClient sharedWiFiClient];
+	if (!client) {
+		return nil;
+	}
+
+	if (name && name[0] != '\0') {
+		return [client interfaceWithName:[NSString stringWithUTF8String:name]];
+	}
+	return [client interface];
+}
+
+int getWiFiRSSI(const char* name) {
 	@autoreleasepool {
-		CWWiFiClient *client = [CWWiFiClient sharedWiFiClient];
-		if (!client) {
+		CWInterface *interface = getWiFiInterface(name);
+		if (!interface) {
 			return 0;
 		}
 
-		CWInterface *interface = [client interface];
+		NSInteger rssi = [interface rssiValue];
+		return (int)rssi;
+	}
+}
+
+// Fills in the SSID (may be empty without location permission), interface name and
+// transmit rate (Mbit/s). Returns 0 if no WiFi interface is available
+int getWiFiDetails(const char* name, char* ssid, int ssidLen, char* ifname, int ifnameLen, double* txRate) {
+	@autoreleasepool {
+		CWInterface *interface = getWiFiInterface(name);
 		if (!interface) {
 			return 0;
 		}
 
-		NSInteger rssi = [interface rssiValue];
-		return (int)rssi;
+		NSString *currentSSID = [interface ssid];
+		if (currentSSID) {
+			strlcpy(ssid, [currentSSID UTF8String], ssidLen);
+		}
+
+		NSString *interfaceName = [interface interfaceName];
+		if (interfaceName) {
+			strlcpy(ifname, [interfaceName UTF8String], ifnameLen);
+		}
+
+		*txRate = [interface transmitRate];
+		return 1;
+	}
+}
+
+// Copies the description of the internal battery, or returns NULL if there is none
+// The caller must release the returned dictionary
+CFDictionaryRef copyInternalBatteryDescription() {
+	CFTypeRef powerSourcesInfo = IOPSCopyPowerSourcesInfo();
+	if (!powerSourcesInfo) {
+		return NULL;
+	}
+
+	CFArrayRef powerSources = IOPSCopyPowerSourcesList(powerSourcesInfo);
+	if (!powerSources) {
+		CFRelease(powerSourcesInfo);
+		return NULL;
+	}
+
+	CFDictionaryRef result = NULL;
+	CFIndex count = CFArrayGetCount(powerSources);
+
+	for (CFIndex i = 0; i < count; i++) {
+		CFTypeRef powerSource = CFArrayGetValueAtIndex(powerSources, i);
+		CFDictionaryRef description = IOPSGetPowerSourceDescription(powerSourcesInfo, powerSource);
+		if (!description) {
+			continue;
+		}
+
+		CFStringRef transportType = CFDictionaryGetValue(description, CFSTR(kIOPSTransportTypeKey));
+		if (transportType && CFStringCompare(transportType, CFSTR(kIOPSInternalType), 0) == kCFCompareEqualTo) {
+			result = CFDictionaryCreateCopy(NULL, description);
+			break;
+		}
+	}
+
+	CFRelease(powerSources);
+	CFRelease(powerSourcesInfo);
+	return result;
+}
+
+// Returns battery voltage in millivolts, or -1 if unavailable
+int getBatteryMillivolts() {
+	@autoreleasepool {
+		CFDictionaryRef description = copyInternalBatteryDescription();
+		if (!description) {
+			return -1;
+		}
+
+		int millivolts = -1;
+		CFNumberRef voltage = CFDictionaryGetValue(description, CFSTR(kIOPSVoltageKey));
+		if (voltage) {
+			CFNumberGetValue(voltage, kCFNumberIntType, &millivolts);
+		}
+
+		CFRelease(description);
+		return millivolts;
+	}
+}
+
+// Returns 1 if charging, 0 if discharging, 2 if charged, 3 if on AC without charging, -1 if unknown
+int getBatteryChargingState() {
+	@autoreleasepool {
+		CFDictionaryRef description = copyInternalBatteryDescription();
+		if (!description) {
+			return -1;
+		}
+
+		int state = -1;
+		CFBooleanRef isCharging = CFDictionaryGetValue(description, CFSTR(kIOPSIsChargingKey));
+		CFBooleanRef isCharged = CFDictionaryGetValue(description, CFSTR(kIOPSIsChargedKey));
+		CFStringRef powerState = CFDictionaryGetValue(description, CFSTR(kIOPSPowerSourceStateKey));
+
+		if (isCharged && CFBooleanGetValue(isCharged)) {
+			state = 2;
+		} else if (isCharging && CFBooleanGetValue(isCharging)) {
+			state = 1;
+		} else if (powerState && CFStringCompare(powerState, CFSTR(kIOPSACPowerValue), 0) == kCFCompareEqualTo) {
+			state = 3;
+		} else if (powerState) {
+			state = 0;
+		}
+
+		CFRelease(description);
+		return state;
+	}
+}
+
+// Returns the system boot time in seconds since the epoch, or -1 if unavailable
+long long getBootTime() {
+	struct timeval bootTime;
+	size_t size = sizeof(bootTime);
+	int mib[2] = {CTL_KERN, KERN_BOOTTIME};
+	if (sysctl(mib, 2, &bootTime, &size, NULL, 0) != 0) {
+		return -1;
 	}
+	return (long long)bootTime.tv_sec;
 }
 
 double getBatteryLevel() {
@@ -76,7 +206,10 @@ double getBatteryLevel() {
 */
 import "C"
 import (
+	"fmt"
 	"net"
+	"time"
+	"unsafe"
 
 	"golang.org/x/net/route"
 )
@@ -174,11 +307,79 @@ func isDefaultRoute(rm *route.RouteMessage) bool {
 }
 
 // getWiFiSignal returns WiFi signal strength (RSSI in dBm) using CoreWLAN framework
-func getWiFiSignal() int {
-	return int(C.getWiFiRSSI())
+func getWiFiSignal(ifaceName string) int {
+	name := C.CString(ifaceName)
+	defer C.free(unsafe.Pointer(name))
+	return int(C.getWiFiRSSI(name))
 }
 
-// getBatteryPercentage returns battery percentage (0-100) or -1 if unavailable using IOKit framework
-func getBatteryPercentage() float64 {
-	return float64(C.getBatteryLevel())
+// GetWiFiInfo returns the wireless link for ifaceName ("" uses the default WiFi interface)
+func GetWiFiInfo(ifaceName string) (WiFiInfo, error) {
+	name := C.CString(ifaceName)
+	defer C.free(unsafe.Pointer(name))
+
+	var ssid [256]C.char
+	var ifname [64]C.char
+	var txRate C.double
+	if C.getWiFiDetails(name, &ssid[0], C.int(len(ssid)), &ifname[0], C.int(len(ifname)), &txRate) == 0 {
+		return WiFiInfo{}, fmt.Errorf("no WiFi interface available")
+	}
+
+	rssi := int(C.getWiFiRSSI(name))
+	return WiFiInfo{
+		Interface:   C.GoString(&ifname[0]),
+		SSID:        C.GoString(&ssid[0]),
+		RSSI:        rssi,
+		Quality:     qualityFromRSSI(rssi),
+		BitrateMbps: float64(txRate),
+		Source:      "CoreWLAN",
+	}, nil
+}
+
+// getBatteryStatus returns the battery percentage (-1 if unavailable), measured voltage
+// and charging state using IOKit framework
+func getBatteryStatus() batteryStatus {
+	return batteryStatus{
+		Percent:  float64(C.getBatteryLevel()),
+		Voltage:  getBatteryVoltage(),
+		Charging: getChargingState(),
+	}
+}
+
+// getBatteryVoltage returns the measured battery voltage (V) or 0 if unavailable using IOKit framework
+func getBatteryVoltage() float64 {
+	millivolts := int(C.getBatteryMillivolts())
+	if millivolts <= 0 {
+		return 0
+	}
+	return float64(millivolts) / 1000
+}
+
+// getChargingState returns the battery charging state using IOKit framework
+func getChargingState() ChargingState {
+	switch C.getBatteryChargingState() {
+	case 0:
+		return ChargingDischarging
+	case 1:
+		return ChargingCharging
+	case 2:
+		return ChargingFull
+	case 3:
+		return ChargingNotCharging
+	}
+	return ChargingUnknown
+}
+
+// getTemperature returns 0 - SMC temperature sensors require private APIs
+func getTemperature() float64 {
+	return 0
+}
+
+// getUptime returns the system uptime based on kern.boottime
+func getUptime() time.Duration {
+	bootTime := int64(C.getBootTime())
+	if bootTime <= 0 {
+		return 0
+	}
+	return time.Since(time.Unix(bootTime, 0))
 }
@@ -9,25 +9,18 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // GetMACAddress returns the MAC address of the primary network interface
+// The default route interface is used so identity survives container/VM bridges
+// appearing or reordering between reboots
 func GetMACAddress() (string, error) {
-	interfaces, err := net.Interfaces()
+	iface, err := primaryInterface()
 	if err != nil {
 		return "", err
 	}
-
-	// Find first non-loopback interface with a MAC address
-	for _, iface := range interfaces {
-		if iface.Flags&net.FlagLoopback == 0 && iface.Flags&net.FlagUp != 0 {
-			if len(iface.HardwareAddr) > 0 {
-				return iface.HardwareAddr.String(), nil
-			}
-		}
-	}
-
-	return "", fmt.Errorf("no network interface found")
+	return iface.HardwareAddr.String(), nil
 }
 
 // GetMACAddressForInterface returns the MAC address for a specific interface
@@ -45,36 +38,53 @@ func GetMACAddressForInterface(ifaceName string) (string, error) {
 }
 
 // GetPrimaryInterfaceName returns the name of the primary network interface
+// This is always the interface GetMACAddress reads from
 func GetPrimaryInterfaceName() string {
-	interfaces, err := net.Interfaces()
+	iface, err := primaryInterface()
 	if err != nil {
 		return "eth0"
 	}
+	return iface.Name
+}
 
-	// Find first non-loopback interface
-	for _, iface := range interfaces {
-		if iface.Flags&net.FlagLoopback == 0 && iface.Flags&net.FlagUp != 0 {
-			return iface.Name
-		}
+// GetWiFiInfo returns the wireless link for ifaceName ("" selects the default route
+// interface, then the first associated wireless interface)
+// nl80211 is used when available, with the deprecated /proc/net/wireless as fallback
+func GetWiFiInfo(ifaceName string) (WiFiInfo, error) {
+	info, err := readNL80211(ifaceName)
+	if err == nil {
+		return info, nil
+	}
+
+	if procInfo, procErr := readProcNetWireless(ifaceName); procErr == nil {
+		return procInfo, nil
 	}
 
-	return "eth0"
+	return WiFiInfo{}, err
 }
 
-// getWiFiSignal returns WiFi signal strength (RSSI in dBm) by reading /proc/net/wireless
-func getWiFiSignal() int {
-	// Try reading from /proc/net/wireless
-	file, err := os.Open("/proc/net/wireless")
+// getWiFiSignal returns WiFi signal strength (RSSI in dBm) or 0 if unavailable
+func getWiFiSignal(ifaceName string) int {
+	info, err := GetWiFiInfo(ifaceName)
 	if err != nil {
 		return 0
 	}
+	return info.RSSI
+}
+
+// readProcNetWireless reads the wireless link from /proc/net/wireless
+func readProcNetWireless(ifaceName string) (WiFiInfo, error) {
+	file, err := os.Open("/proc/net/wireless")
+	if err != nil {
+		return WiFiInfo{}, err
+	}
 	defer file.Close()
 
 	scanner := bufio.NewScanner(file)
 
 	// Skip the first two header lines
 	if !scanner.Scan() || !scanner.Scan() {
-		return 0
+		return WiFiInfo{}, fmt.Errorf("/proc/net/wireless is empty")
 	}
 
 	// Read wireless interface data
@@ -85,35 +95,104 @@ func getWiFiSignal() int {
 		fields := strings.Fields(line)
 
 		// Need at least 4 fields: interface, status, link, level
-		if len(fields) >= 4 {
-			// The signal level is in the 4th field (index 3)
-			// It's typically in dBm and has a trailing dot
-			levelStr := strings.TrimSuffix(fields[3], ".")
-			if level, err := strconv.ParseFloat(levelStr, 64); err == nil {
-				return int(level)
+		if len(fields) < 4 {
+			continue
+		}
+
+		name := strings.TrimSuffix(fields[0], ":")
+		if ifaceName != "" && name != ifaceName {
+			continue
+		}
+
+		// The signal level is in the 4th field (index 3)
+		// It's typically in dBm and has a trailing dot
+		levelStr := strings.TrimSuffix(fields[3], ".")
+		level, err := strconv.ParseFloat(levelStr, 64)
+		if err != nil {
+			continue
+		}
+
+		info := WiFiInfo{
+			Interface: name,
+			RSSI:      int(level),
+			Quality:   qualityFromRSSI(int(level)),
+			Source:    "/proc/net/wireless",
+		}
+
+		// Link quality is reported out of 70 by most drivers
+		if link, err := strconv.ParseFloat(strings.TrimSuffix(fields[2], "."), 64); err == nil && link > 0 {
+			info.Quality = int(link / 70 * 100)
+			if info.Quality > 100 {
+				info.Quality = 100
 			}
 		}
+
+		return info, nil
+	}
+
+	return WiFiInfo{}, fmt.Errorf("no wireless interface in /proc/net/wireless")
+}
+
+// getBatteryStatus returns the battery percentage, measured voltage and charging state
+// All batteries under /sys/class/power_supply are aggregated, reading sysfs once
+func getBatteryStatus() batteryStatus {
+	status, ok := readBatteryStatus(powerSupplyRoot)
+	if !ok {
+		return batteryStatus{Percent: -1}
+	}
+	return status
+}
+
+// parseChargingStatus maps a sysfs power supply status to a ChargingState
+func parseChargingStatus(status string) ChargingState {
+	switch strings.TrimSpace(status) {
+	case "Charging":
+		return ChargingCharging
+	case "Discharging":
+		return ChargingDischarging
+	case "Full":
+		return ChargingFull
+	case "Not charging":
+		return ChargingNotCharging
 	}
+	return ChargingUnknown
+}
 
+// getTemperature returns the first thermal zone temperature (°C) or 0 if unavailable
+func getTemperature() float64 {
+	// Thermal zones report millidegrees Celsius
+	if millidegrees, err := readSysfsFloat("/sys/class/thermal/thermal_zone0/temp"); err == nil {
+		return millidegrees / 1000
+	}
 	return 0
 }
 
-// getBatteryPercentage returns battery percentage (0-100) or -1 if unavailable
-func getBatteryPercentage() float64 {
-	// Try reading from /sys/class/power_supply/BAT0/capacity
-	data, err := os.ReadFile("/sys/class/power_supply/BAT0/capacity")
+// getUptime returns the system uptime from /proc/uptime or 0 if unavailable
+func getUptime() time.Duration {
+	data, err := os.ReadFile("/proc/uptime")
 	if err != nil {
-		// Try BAT1
-		data, err = os.ReadFile("/sys/class/power_supply/BAT1/capacity")
-		if err != nil {
-			return -1
-		}
+		return 0
+	}
+
+	// Format: "<uptime seconds> <idle seconds>"
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0
 	}
 
-	percentStr := strings.TrimSpace(string(data))
-	if percent, err := strconv.ParseFloat(percentStr, 64); err == nil {
-		return percent
+	seconds, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0
 	}
 
-	return -1
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// readSysfsFloat reads a single numeric value from a sysfs attribute file
+func readSysfsFloat(path string) (float64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
 }
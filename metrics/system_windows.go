@@ -6,6 +6,30 @@ package metrics
 #cgo LDFLAGS: -lsetupapi -lwlanapi -lole32
 #include <windows.h>
 #include <wlanapi.h>
+#include <string.h>
+
+// Returns 1 if charging, 0 if discharging, 2 if on AC without charging, -1 if unknown
+int getWindowsChargingState() {
+    SYSTEM_POWER_STATUS status;
+    if (!GetSystemPowerStatus(&status)) {
+        return -1;
+    }
+    // BatteryFlag 128 = no system battery, 255 = unknown status
+    if (status.BatteryFlag == 128 || status.BatteryFlag == 255) {
+        return -1;
+    }
+    if (status.BatteryFlag & 8) {
+        return 1;
+    }
+    if (status.ACLineStatus == 1) {
+        return 2;
+    }
+    return 0;
+}
+
+unsigned long long getWindowsUptimeMillis() {
+    return GetTickCount64();
+}
 
 double getWindowsBatteryLevel() {
     SYSTEM_POWER_STATUS status;
@@ -17,6 +41,51 @@ double getWindowsBatteryLevel() {
     return -1.0;
 }
 
+// Fills in the first WLAN connection's signal quality (0-100), SSID and transmit rate (kbit/s)
+// Returns 0 if no connection is available
+int getWindowsWiFiDetails(int* quality, char* ssid, int ssidLen, unsigned long* txRate) {
+    HANDLE hClient = NULL;
+    DWORD dwCurVersion = 0;
+    int found = 0;
+
+    if (WlanOpenHandle(2, NULL, &dwCurVersion, &hClient) != ERROR_SUCCESS) {
+        return 0;
+    }
+
+    PWLAN_INTERFACE_INFO_LIST pIfList = NULL;
+    if (WlanEnumInterfaces(hClient, NULL, &pIfList) != ERROR_SUCCESS) {
+        WlanCloseHandle(hClient, NULL);
+        return 0;
+    }
+
+    if (pIfList->dwNumberOfItems > 0) {
+        PWLAN_CONNECTION_ATTRIBUTES pConnectInfo = NULL;
+        DWORD connectInfoSize = sizeof(WLAN_CONNECTION_ATTRIBUTES);
+        WLAN_OPCODE_VALUE_TYPE opCode = wlan_opcode_value_type_invalid;
+
+        if (WlanQueryInterface(hClient, &pIfList->InterfaceInfo[0].InterfaceGuid,
+                wlan_intf_opcode_current_connection, NULL, &connectInfoSize,
+                (PVOID*)&pConnectInfo, &opCode) == ERROR_SUCCESS && pConnectInfo != NULL) {
+            DOT11_SSID dot11Ssid = pConnectInfo->wlanAssociationAttributes.dot11Ssid;
+            int length = (int)dot11Ssid.uSSIDLength;
+            if (length > ssidLen - 1) {
+                length = ssidLen - 1;
+            }
+            memcpy(ssid, dot11Ssid.ucSSID, length);
+            ssid[length] = '\0';
+
+            *quality = (int)pConnectInfo->wlanAssociationAttributes.wlanSignalQuality;
+            *txRate = pConnectInfo->wlanAssociationAttributes.ulTxRate;
+            found = 1;
+            WlanFreeMemory(pConnectInfo);
+        }
+    }
+
+    WlanFreeMemory(pIfList);
+    WlanCloseHandle(hClient, NULL);
+    return found;
+}
+
 int getWindowsWiFiRSSI() {
     HANDLE hClient = NULL;
     DWORD dwMaxClient = 2;
@@ -80,6 +149,7 @@ import "C"
 import (
 	"fmt"
 	"net"
+	"time"
 )
 
 // GetMACAddress returns the MAC address of the primary network interface
@@ -133,11 +203,59 @@ func GetPrimaryInterfaceName() string {
 }
 
 // getWiFiSignal returns WiFi signal strength (RSSI in dBm) using Windows WLAN API
-func getWiFiSignal() int {
+// The interface name is ignored; the first WLAN interface is used
+func getWiFiSignal(ifaceName string) int {
 	return int(C.getWindowsWiFiRSSI())
 }
 
-// getBatteryPercentage returns battery percentage (0-100) or -1 if unavailable using Windows API
-func getBatteryPercentage() float64 {
-	return float64(C.getWindowsBatteryLevel())
+// GetWiFiInfo returns the first WLAN connection using Windows WLAN API
+func GetWiFiInfo(ifaceName string) (WiFiInfo, error) {
+	var quality C.int
+	var ssid [33]C.char
+	var txRate C.ulong
+	if C.getWindowsWiFiDetails(&quality, &ssid[0], C.int(len(ssid)), &txRate) == 0 {
+		return WiFiInfo{}, fmt.Errorf("no WLAN connection available")
+	}
+
+	return WiFiInfo{
+		Interface: ifaceName,
+		SSID:      C.GoString(&ssid[0]),
+		// Same approximation as getWindowsWiFiRSSI
+		RSSI:        -100 + int(quality)/2,
+		Quality:     int(quality),
+		BitrateMbps: float64(txRate) / 1000,
+		Source:      "WLAN API",
+	}, nil
+}
+
+// getBatteryStatus returns the battery percentage (-1 if unavailable) and charging state using Windows API
+// The voltage is 0 - Windows does not expose battery voltage via GetSystemPowerStatus
+func getBatteryStatus() batteryStatus {
+	return batteryStatus{
+		Percent:  float64(C.getWindowsBatteryLevel()),
+		Charging: getChargingState(),
+	}
+}
+
+// getChargingState returns the battery charging state using Windows API
+func getChargingState() ChargingState {
+	switch C.getWindowsChargingState() {
+	case 0:
+		return ChargingDischarging
+	case 1:
+		return ChargingCharging
+	case 2:
+		return ChargingNotCharging
+	}
+	return ChargingUnknown
+}
+
+// getTemperature returns 0 - no portable temperature source on Windows
+func getTemperature() float64 {
+	return 0
+}
+
+// getUptime returns the system uptime using GetTickCount64
+func getUptime() time.Duration {
+	return time.Duration(C.getWindowsUptimeMillis()) * time.Millisecond
 }
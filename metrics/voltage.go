@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+)
+
+// VoltagePoint maps a battery percentage to a voltage on a discharge curve
+type VoltagePoint struct {
+	Percent float64 `json:"percent"`
+	Voltage float64 `json:"voltage"`
+}
+
+// VoltageCurve is a percentage-to-voltage table, interpolated linearly between points
+// An empty curve uses DefaultVoltage
+type VoltageCurve []VoltagePoint
+
+// Default discharge curve voltages
+const (
+	MinDefaultVoltage = 3.0  // Empty battery
+	MaxDefaultVoltage = 4.08 // Full charge
+)
+
+// DefaultVoltage approximates a Li-ion discharge curve (3.0V-4.08V) matching the
+// TRMNL firmware: linear from 1-83%, rising to 4.02V at 90%, then steps of
+// 4.02V (90-95%), 4.06V (95-100%) and 4.08V (full charge)
+func DefaultVoltage(percentage float64) float64 {
+	switch {
+	case percentage >= 100:
+		return MaxDefaultVoltage
+	case percentage >= 95:
+		return 4.06 // midpoint of 95-100% block
+	case percentage >= 90:
+		return 4.02 // midpoint of 90-95% block
+	case percentage <= 1:
+		return MinDefaultVoltage
+	case percentage <= 83:
+		// Linear band: V = 3.0 + pct * 0.012
+		return MinDefaultVoltage + percentage*0.012
+	}
+	// 83-90% band
+	return 3.996 + (percentage-83)*0.003429 // ~4.02V at 90%
+}
+
+// Validate checks that the curve has at least two points with increasing percentages
+func (c VoltageCurve) Validate() error {
+	if len(c) < 2 {
+		return fmt.Errorf("voltage curve needs at least 2 points")
+	}
+	for i, point := range c {
+		if point.Percent < 0 || point.Percent > 100 {
+			return fmt.Errorf("voltage curve point %d: percent %.1f out of range 0-100", i, point.Percent)
+		}
+		if point.Voltage <= 0 {
+			return fmt.Errorf("voltage curve point %d: voltage must be positive", i)
+		}
+		if i > 0 && point.Percent <= c[i-1].Percent {
+			return fmt.Errorf("voltage curve point %d: percentages must be strictly increasing", i)
+		}
+	}
+	return nil
+}
+
+// Voltage returns the voltage for a battery percentage
+// Percentages outside the table are clamped to the first/last point
+func (c VoltageCurve) Voltage(percentage float64) float64 {
+	if len(c) == 0 {
+		return DefaultVoltage(percentage)
+	}
+
+	if percentage <= c[0].Percent {
+		return c[0].Voltage
+	}
+	last := c[len(c)-1]
+	if percentage >= last.Percent {
+		return last.Voltage
+	}
+
+	// Find the first point at or above the percentage and interpolate
+	i := sort.Search(len(c), func(i int) bool { return c[i].Percent >= percentage })
+	lower, upper := c[i-1], c[i]
+	fraction := (percentage - lower.Percent) / (upper.Percent - lower.Percent)
+	return lower.Voltage + fraction*(upper.Voltage-lower.Voltage)
+}
@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WiFiInfo describes the current wireless link
+type WiFiInfo struct {
+	Interface   string  // Interface name (e.g. wlan0, en0)
+	SSID        string  // Network name, empty if unavailable
+	RSSI        int     // Signal strength (dBm), 0 if unavailable
+	Quality     int     // Link quality (0-100), -1 if unavailable
+	BitrateMbps float64 // Transmit bitrate (Mbit/s), 0 if unavailable
+	Source      string  // Where the reading came from (e.g. nl80211, /proc/net/wireless)
+}
+
+// String returns a human-readable representation of the link
+func (w WiFiInfo) String() string {
+	parts := []string{}
+	if w.Interface != "" {
+		parts = append(parts, w.Interface)
+	}
+	if w.SSID != "" {
+		parts = append(parts, fmt.Sprintf("SSID %q", w.SSID))
+	}
+	parts = append(parts, fmt.Sprintf("%d dBm", w.RSSI))
+	if w.Quality >= 0 {
+		parts = append(parts, fmt.Sprintf("quality %d%%", w.Quality))
+	}
+	if w.BitrateMbps > 0 {
+		parts = append(parts, fmt.Sprintf("%.1f Mbit/s", w.BitrateMbps))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Details returns the link as a map suitable for structured log entries
+func (w WiFiInfo) Details() map[string]any {
+	details := map[string]any{
+		"interface": w.Interface,
+		"rssi":      w.RSSI,
+		"source":    w.Source,
+	}
+	if w.SSID != "" {
+		details["ssid"] = w.SSID
+	}
+	if w.Quality >= 0 {
+		details["quality"] = w.Quality
+	}
+	if w.BitrateMbps > 0 {
+		details["bitrate_mbps"] = w.BitrateMbps
+	}
+	return details
+}
+
+// qualityFromRSSI estimates link quality (0-100) from signal strength,
+// mapping -100 dBm to 0% and -50 dBm or better to 100%
+func qualityFromRSSI(rssi int) int {
+	if rssi == 0 {
+		return -1
+	}
+	quality := 2 * (rssi + 100)
+	if quality < 0 {
+		return 0
+	}
+	if quality > 100 {
+		return 100
+	}
+	return quality
+}
@@ -23,6 +23,7 @@ const (
 	ErrorTitleOffsetY  = 60  // Offset from center for error titles
 	ErrorMessageStartY = 20  // Starting Y offset below center for error messages
 	MaxLineWrapChars   = 60  // Maximum characters per line for text wrapping
+	CaptionHeight      = 20  // Height of the bar drawn by DrawCaption
 )
 
 // GenerateStartupScreen creates a TRMNL startup/splash screen
@@ -85,6 +86,16 @@ func GenerateErrorScreen(width, height int, errorTitle, errorMessage string) ([]
 	return buf.Bytes(), nil
 }
 
+// DrawCaption draws a line of text in a black bar along the bottom edge of an image
+func DrawCaption(img *image.RGBA, caption string) {
+	bounds := img.Bounds()
+	bar := image.Rect(bounds.Min.X, bounds.Max.Y-CaptionHeight, bounds.Max.X, bounds.Max.Y)
+	draw.Draw(img, bar, &image.Uniform{color.Black}, image.Point{}, draw.Src)
+
+	// Center the 13px font vertically in the bar
+	drawCenteredText(img, bounds.Dx(), bounds.Max.Y-CaptionHeight/2+4, caption, color.White)
+}
+
 // drawCenteredText draws text centered horizontally at the given Y position
 func drawCenteredText(img *image.RGBA, width, y int, text string, col color.Color) {
 	// Use basic font (we'll use a simple monospace font)
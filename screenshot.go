@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/semaja2/trmnl-go/config"
+	"github.com/semaja2/trmnl-go/display"
+)
+
+// saveScreenshot writes the frame on screen to the configured screenshot path
+func (a *App) saveScreenshot() {
+	frame := a.shownFrame()
+	path, err := display.SaveScreenshot(frame, a.config, a.config.ScreenshotPath, a.config.ScreenshotFormat)
+	if err != nil {
+		log.Printf("Failed to save screenshot: %v", err)
+		a.window.UpdateStatus(fmt.Sprintf("Error saving screenshot: %v", err))
+		return
+	}
+
+	if a.verbose {
+		fmt.Printf("[App] Screenshot saved to %s\n", path)
+	}
+	a.logger.Info("Screenshot saved", map[string]any{
+		"path":     path,
+		"filename": frame.Filename,
+	})
+	a.window.UpdateStatus(fmt.Sprintf("Saved %s", path))
+}
+
+// runScreenshot fetches the current frame once, saves it to the -screenshot
+// file and returns the exit code
+func runScreenshot(cfg *config.Config) int {
+	cfg, err := singleDeviceConfig(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	_, frame, err := fetchOnce(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	path, err := display.SaveScreenshot(frame, cfg, *screenshot, cfg.ScreenshotFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Println(path)
+	return 0
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/semaja2/trmnl-go/api"
+)
+
+// replaying reports whether API requests are answered from a recorded session
+// Nothing is saved to the config then, as the recorded setup responses hold
+// redacted API keys and belong to another machine's device
+func replaying() bool {
+	return *replaySession != ""
+}
+
+// startSession records or replays API traffic as selected by -record and -replay
+// It must run before any API client is created; the returned function finishes the recording
+func startSession(verbose bool) (func(), error) {
+	switch {
+	case *recordSession != "" && *replaySession != "":
+		return nil, fmt.Errorf("-record and -replay cannot be used together")
+
+	case *recordSession != "":
+		recorder, err := api.NewRecorder(*recordSession, api.SharedTransport())
+		if err != nil {
+			return nil, err
+		}
+		api.SetSharedTransport(recorder)
+		if verbose {
+			fmt.Printf("[App] Recording API session to %s\n", *recordSession)
+		}
+		return func() {
+			if err := recorder.Close(); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+		}, nil
+
+	case *replaySession != "":
+		replayer, err := api.NewReplayer(*replaySession, *replaySpeed)
+		if err != nil {
+			return nil, err
+		}
+		api.SetSharedTransport(replayer)
+		if verbose {
+			fmt.Printf("[App] Replaying API session from %s (speed %gx)\n", *replaySession, *replaySpeed)
+		}
+	}
+	return func() {}, nil
+}
@@ -0,0 +1,303 @@
+package telemetry
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/semaja2/trmnl-go/metrics"
+)
+
+// Fetch results used as the "result" label
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// ImageEndpoint is the "endpoint" label of image downloads, whose URLs change
+// with every frame; API requests are labelled with their path
+const ImageEndpoint = "image"
+
+// Default histogram buckets (in seconds)
+var (
+	DownloadBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+	RenderBuckets   = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5}
+)
+
+// fetchKey identifies a fetch counter series
+type fetchKey struct {
+	endpoint string
+	result   string
+}
+
+// histogram is a minimal cumulative histogram in Prometheus format
+type histogram struct {
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{
+		buckets: buckets,
+		counts:  make([]uint64, len(buckets)),
+	}
+}
+
+func (h *histogram) observe(v float64) {
+	for i, upper := range h.buckets {
+		if v <= upper {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+// Collector records runtime statistics for the virtual display and exposes
+// them in the Prometheus text exposition format.
+// All methods are safe to call on a nil *Collector, so callers don't need
+// to check whether the metrics endpoint is enabled.
+type Collector struct {
+	mu              sync.Mutex
+	device          string // Value of the "device" label (empty omits the label)
+	fetches         map[fetchKey]uint64
+	downloadBytes   uint64
+	downloadSeconds *histogram
+	renderSeconds   *histogram
+	refreshRate     int
+	lastSuccess     time.Time
+	system          metrics.SystemMetrics
+	hasSystem       bool
+	logFlushes      map[string]uint64
+}
+
+// NewCollector creates an empty collector
+func NewCollector() *Collector {
+	return &Collector{
+		fetches:         make(map[fetchKey]uint64),
+		downloadSeconds: newHistogram(DownloadBuckets),
+		renderSeconds:   newHistogram(RenderBuckets),
+		logFlushes:      make(map[string]uint64),
+	}
+}
+
+// ObserveFetch counts an API request outcome for the given endpoint
+func (c *Collector) ObserveFetch(endpoint string, err error) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.fetches[fetchKey{endpoint: endpoint, result: resultLabel(err)}]++
+}
+
+// ObserveImageDownload records a successful image download
+func (c *Collector) ObserveImageDownload(bytes int, duration time.Duration) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.downloadBytes += uint64(bytes)
+	c.downloadSeconds.observe(duration.Seconds())
+}
+
+// ObserveRender records how long it took to transform and display a frame
+func (c *Collector) ObserveRender(duration time.Duration) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.renderSeconds.observe(duration.Seconds())
+}
+
+// SetRefreshRate records the refresh rate currently in use (seconds)
+func (c *Collector) SetRefreshRate(seconds int) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.refreshRate = seconds
+}
+
+// MarkSuccess records the time of the last successful display update
+func (c *Collector) MarkSuccess(t time.Time) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lastSuccess = t
+}
+
+// SetSystemMetrics records the battery and WiFi values reported to the server
+func (c *Collector) SetSystemMetrics(m metrics.SystemMetrics) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.system = m
+	c.hasSystem = true
+}
+
+// ObserveLogFlush counts a log upload attempt
+func (c *Collector) ObserveLogFlush(err error) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.logFlushes[resultLabel(err)]++
+}
+
+// WriteTo writes all metrics in the Prometheus text exposition format
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	return writeMetrics(w, []*Collector{c})
+}
+
+// writeMetrics writes the metrics of several collectors, grouping the series
+// of each metric family under a single header as the exposition format requires
+func writeMetrics(w io.Writer, collectors []*Collector) (int64, error) {
+	for _, c := range collectors {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+	}
+
+	var b strings.Builder
+
+	writeHeader(&b, "trmnl_fetch_total", "counter", "API requests by endpoint and result.")
+	for _, c := range collectors {
+		keys := make([]fetchKey, 0, len(c.fetches))
+		for k := range c.fetches {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i].endpoint != keys[j].endpoint {
+				return keys[i].endpoint < keys[j].endpoint
+			}
+			return keys[i].result < keys[j].result
+		})
+		for _, k := range keys {
+			fmt.Fprintf(&b, "trmnl_fetch_total%s %d\n", c.labels("endpoint", k.endpoint, "result", k.result), c.fetches[k])
+		}
+	}
+
+	writeHeader(&b, "trmnl_image_download_bytes_total", "counter", "Total bytes of image data downloaded.")
+	for _, c := range collectors {
+		fmt.Fprintf(&b, "trmnl_image_download_bytes_total%s %d\n", c.labels(), c.downloadBytes)
+	}
+
+	writeHeader(&b, "trmnl_image_download_duration_seconds", "histogram", "Image download latency.")
+	for _, c := range collectors {
+		writeHistogram(&b, "trmnl_image_download_duration_seconds", c, c.downloadSeconds)
+	}
+
+	writeHeader(&b, "trmnl_render_duration_seconds", "histogram", "Time spent transforming and displaying a frame.")
+	for _, c := range collectors {
+		writeHistogram(&b, "trmnl_render_duration_seconds", c, c.renderSeconds)
+	}
+
+	writeHeader(&b, "trmnl_refresh_rate_seconds", "gauge", "Refresh rate currently in use.")
+	for _, c := range collectors {
+		fmt.Fprintf(&b, "trmnl_refresh_rate_seconds%s %d\n", c.labels(), c.refreshRate)
+	}
+
+	writeHeader(&b, "trmnl_last_success_timestamp_seconds", "gauge", "Unix time of the last successful display update.")
+	for _, c := range collectors {
+		lastSuccess := 0.0
+		if !c.lastSuccess.IsZero() {
+			lastSuccess = float64(c.lastSuccess.UnixNano()) / 1e9
+		}
+		fmt.Fprintf(&b, "trmnl_last_success_timestamp_seconds%s %s\n", c.labels(), formatFloat(lastSuccess))
+	}
+
+	var reporting []*Collector
+	for _, c := range collectors {
+		if c.hasSystem {
+			reporting = append(reporting, c)
+		}
+	}
+	if len(reporting) > 0 {
+		writeHeader(&b, "trmnl_battery_percent", "gauge", "Battery percentage reported to the server.")
+		for _, c := range reporting {
+			fmt.Fprintf(&b, "trmnl_battery_percent%s %s\n", c.labels(), formatFloat(c.system.BatteryVoltage))
+		}
+
+		writeHeader(&b, "trmnl_wifi_rssi_dbm", "gauge", "WiFi signal strength reported to the server.")
+		for _, c := range reporting {
+			fmt.Fprintf(&b, "trmnl_wifi_rssi_dbm%s %d\n", c.labels(), c.system.RSSI)
+		}
+	}
+
+	writeHeader(&b, "trmnl_log_flush_total", "counter", "Log uploads by result.")
+	for _, c := range collectors {
+		for _, result := range []string{ResultSuccess, ResultFailure} {
+			fmt.Fprintf(&b, "trmnl_log_flush_total%s %d\n", c.labels("result", result), c.logFlushes[result])
+		}
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// labels formats a label set from name/value pairs, prefixed with the device label if set
+func (c *Collector) labels(pairs ...string) string {
+	if c.device != "" {
+		pairs = append([]string{"device", c.device}, pairs...)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+
+	parts := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		parts = append(parts, fmt.Sprintf("%s=%q", pairs[i], pairs[i+1]))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// resultLabel maps an error to a result label value
+func resultLabel(err error) string {
+	if err != nil {
+		return ResultFailure
+	}
+	return ResultSuccess
+}
+
+func writeHeader(b *strings.Builder, name, kind, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(b, "# TYPE %s %s\n", name, kind)
+}
+
+func writeHistogram(b *strings.Builder, name string, c *Collector, h *histogram) {
+	for i, upper := range h.buckets {
+		fmt.Fprintf(b, "%s_bucket%s %d\n", name, c.labels("le", formatFloat(upper)), h.counts[i])
+	}
+	fmt.Fprintf(b, "%s_bucket%s %d\n", name, c.labels("le", "+Inf"), h.count)
+	fmt.Fprintf(b, "%s_sum%s %s\n", name, c.labels(), formatFloat(h.sum))
+	fmt.Fprintf(b, "%s_count%s %d\n", name, c.labels(), h.count)
+}
+
+// formatFloat formats a float the way Prometheus expects
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return fmt.Sprintf("%g", v)
+}
@@ -0,0 +1,51 @@
+package telemetry
+
+import (
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Registry exposes the metrics of several collectors on a single endpoint
+// Used in multi-device mode, where each device's series carry a "device" label
+type Registry struct {
+	mu         sync.Mutex
+	collectors []*Collector
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Collector creates a collector whose series are labelled with the device name
+// An empty name omits the label
+func (r *Registry) Collector(device string) *Collector {
+	c := NewCollector()
+	c.device = device
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors = append(r.collectors, c)
+
+	return c
+}
+
+// WriteTo writes the metrics of all collectors in the Prometheus text exposition format
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	collectors := append([]*Collector(nil), r.collectors...)
+	r.mu.Unlock()
+
+	return writeMetrics(w, collectors)
+}
+
+// Handler returns an http.Handler serving the metrics of all collectors
+func (r *Registry) Handler() http.Handler {
+	return metricsHandler(r)
+}
+
+// Serve starts an HTTP server exposing /metrics for all collectors on addr
+func (r *Registry) Serve(addr string) (*http.Server, error) {
+	return serve(addr, r.Handler())
+}
@@ -0,0 +1,54 @@
+package telemetry
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// MetricsPath is the HTTP path the metrics are served on
+const MetricsPath = "/metrics"
+
+// Handler returns an http.Handler serving the collector's metrics
+func (c *Collector) Handler() http.Handler {
+	return metricsHandler(c)
+}
+
+// Serve starts an HTTP server exposing /metrics on addr (e.g. ":9100")
+// The listener is opened synchronously so address errors are reported to the caller;
+// requests are then served in a background goroutine.
+func (c *Collector) Serve(addr string) (*http.Server, error) {
+	return serve(addr, c.Handler())
+}
+
+// metricsHandler serves the exposition written by src
+func metricsHandler(src io.WriterTo) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if _, err := src.WriteTo(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// serve listens on addr and serves handler at MetricsPath in the background
+func serve(addr string, handler http.Handler) (*http.Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(MetricsPath, handler)
+
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go server.Serve(listener)
+
+	return server, nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/semaja2/trmnl-go/config"
+	"github.com/semaja2/trmnl-go/display"
+	"github.com/semaja2/trmnl-go/history"
+)
+
+// defaultTimelapseSpeed plays frames 300 times faster than they were shown,
+// so a 15 minute refresh lasts 3 seconds
+const defaultTimelapseSpeed = 300
+
+const timelapseUsage = `Usage: trmnl-go timelapse [flags]
+
+Exports the frame history as an animated GIF. Each frame stays on screen for
+as long as it was shown on the display, sped up by -speed. Frames get the
+configured rotation, dark mode and e-paper processing. At most the newest
+%d frames are exported.
+
+Flags are the same as for trmnl-go (e.g. -profile staging), plus:
+  -out string       File to save the GIF to (placeholders as in screenshot_path,
+                    default ` + display.DefaultTimelapsePath + `)
+  -since duration   Only export frames received in this period (e.g. 24h)
+  -speed float      How many times faster than real time to play (default 300);
+                    a GIF frame lasts at most 655 seconds, so at -speed 1
+                    longer refreshes (e.g. 15 minutes) are cut short
+  -captions         Caption frames with their time and filename (default true)
+`
+
+// windowExporter is implemented by windows with an export timelapse action (Cmd+E / Ctrl+E)
+type windowExporter interface {
+	SetOnExport(func())
+}
+
+// runTimelapseCommand runs "trmnl-go timelapse" and returns the exit code
+func runTimelapseCommand(args []string) int {
+	out := flag.String("out", "", "File to save the GIF to (placeholders as in screenshot_path)")
+	since := flag.Duration("since", 0, "Only export frames received in this period (e.g. 24h)")
+	speed := flag.Float64("speed", defaultTimelapseSpeed, "How many times faster than real time to play")
+	captions := flag.Bool("captions", true, "Caption frames with their time and filename")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, timelapseUsage, display.MaxTimelapseFrames)
+	}
+	flag.CommandLine.Parse(args)
+	if flag.NArg() > 0 || *speed <= 0 {
+		fmt.Fprintf(os.Stderr, timelapseUsage, display.MaxTimelapseFrames)
+		return 2
+	}
+
+	cfg, err := loadConfig()
+	if err == nil {
+		err = cfg.ValidateSettings()
+	}
+	if err == nil {
+		cfg, err = singleDeviceConfig(cfg)
+	}
+	if err == nil && cfg.HistorySize <= 0 {
+		err = fmt.Errorf("frame history is disabled (history_size is 0)")
+	}
+	var dir string
+	if err == nil {
+		dir, err = history.Dir(cfg.Profile(), cfg.DeviceName())
+	}
+	var frames *history.History
+	if err == nil {
+		frames, err = history.Open(dir, cfg.HistorySize)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	var after time.Time
+	if *since > 0 {
+		after = time.Now().Add(-*since)
+	}
+	entries := frames.Recent(after, display.MaxTimelapseFrames)
+
+	path, err := display.SaveTimelapse(timelapseFrames(entries, *speed), cfg, *out, *captions)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Println(path)
+	return 0
+}
+
+// exportTimelapse saves the frame history as an animated GIF next to screenshots
+// The GIF is encoded in the background, so refreshes carry on meanwhile; a
+// second export is ignored until the first one finishes
+func (a *App) exportTimelapse() {
+	if a.history == nil {
+		a.setStatus("Frame history is disabled")
+		return
+	}
+	if !a.exporting.CompareAndSwap(false, true) {
+		return
+	}
+
+	a.setStatus("Exporting history...")
+	cfg := *a.config // Rotation and other settings may change during the export
+	go func() {
+		defer a.exporting.Store(false)
+		a.finishExport(a.saveTimelapse(&cfg))
+	}()
+}
+
+// saveTimelapse encodes and saves the newest history frames, returning the status to show
+func (a *App) saveTimelapse(cfg *config.Config) string {
+	entries := a.history.Recent(time.Time{}, display.MaxTimelapseFrames)
+	path, err := display.SaveTimelapse(timelapseFrames(entries, defaultTimelapseSpeed), cfg, "", true)
+	if err != nil {
+		log.Printf("Failed to export timelapse: %v", err)
+		return fmt.Sprintf("Error exporting history: %v", err)
+	}
+
+	if a.verbose {
+		fmt.Printf("[App] Timelapse of %d frames saved to %s\n", len(entries), path)
+	}
+	a.logger.Info("Timelapse saved", map[string]any{
+		"path":   path,
+		"frames": len(entries),
+	})
+	return fmt.Sprintf("Saved %s", path)
+}
+
+// finishExport hands the outcome of a background export to the refresh loop,
+// which shows it like other statuses (kept for later while browsing the history)
+func (a *App) finishExport(status string) {
+	select {
+	case a.exportedCh <- status:
+	case <-a.stopCh:
+	}
+}
+
+// timelapseFrames times history frames by how long each was shown, divided by speed
+// A frame is shown until the next one was received; gaps longer than twice its
+// refresh rate (the app wasn't running) count as one refresh instead
+func timelapseFrames(entries []history.Entry, speed float64) []display.TimelapseFrame {
+	frames := make([]display.TimelapseFrame, 0, len(entries))
+	for i, entry := range entries {
+		refresh := time.Duration(entry.RefreshRate) * time.Second
+		if refresh <= 0 {
+			refresh = time.Minute
+		}
+
+		shown := refresh
+		if i+1 < len(entries) {
+			if gap := entries[i+1].Frame.Time.Sub(entry.Frame.Time); gap > 0 && gap <= 2*refresh {
+				shown = gap
+			}
+		}
+
+		frames = append(frames, display.TimelapseFrame{
+			Frame:    entry.Frame,
+			Duration: time.Duration(float64(shown) / speed),
+		})
+	}
+	return frames
+}